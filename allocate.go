@@ -0,0 +1,189 @@
+package tomath
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/shopspring/decimal"
+)
+
+// Remainder selects how Allocate() and Split() distribute what is left once
+// every part is rounded.
+type Remainder int
+
+const (
+	// RemainderLargest truncates every share and adds one unit of the last
+	// place to the parts which lost the most to the truncation, the first ones
+	// on ties.
+	RemainderLargest Remainder = iota
+	// RemainderFirst truncates every share and adds one unit of the last place
+	// to the first parts which lost anything to the truncation.
+	RemainderFirst
+	// RemainderBank rounds every share half to even and spreads the difference
+	// with the total, which may be negative, one unit of the last place at a
+	// time over the parts rounded the furthest from their share. On ties it
+	// prefers the parts the unit makes even, then the last parts, so that equal
+	// shares give parts differing by at most one unit.
+	RemainderBank
+)
+
+// adjustment names the value added to a part to distribute the remainder.
+const adjustment = "adjustment"
+
+// Allocate splits d in parts proportional to ratios which sum exactly to d,
+// with as many decimal places as d. The remainder is distributed with
+// RemainderLargest. It panics if a ratio is negative or if they are all zero.
+//
+// Example:
+//
+//     parts := RequireFromStringWithName("total", "100.00").Allocate(
+//         NewFromIntWithName("alice", 1),
+//         NewFromIntWithName("bob", 1),
+//         NewFromIntWithName("carol", 1),
+//     )
+//     // parts: 33.34, 33.33, 33.33
+//     vars, formula := parts[0].Math()
+//     // vars:    "quoRem(2)(total * alice / sum(alice, bob, carol)) + adjustment = ?"
+//     // formula: "quoRem(2)(100 * 1 / sum(1, 1, 1)) + 0.01 = 33.34"
+func (d Decimal) Allocate(ratios ...Decimal) []Decimal {
+	return d.AllocateWith(RemainderLargest, ratios...)
+}
+
+// AllocateWith splits d in parts proportional to ratios as Allocate() does,
+// distributing the remainder with r.
+func (d Decimal) AllocateWith(r Remainder, ratios ...Decimal) []Decimal {
+	if len(ratios) == 0 {
+		panic("tomath: no allocation ratios")
+	}
+	for _, ratio := range ratios {
+		if ratio.Sign() < 0 {
+			panic("tomath: negative allocation ratio " + ratio.String())
+		}
+	}
+	total := Sum(ratios[0], ratios[1:]...)
+	if total.IsZero() {
+		panic("tomath: allocation ratios sum to zero")
+	}
+
+	places := -d.Exponent()
+	if places < 0 {
+		places = 0
+	}
+
+	return d.allocate(len(ratios), places, r, func(i int) Decimal {
+		return d.Mul(ratios[i])
+	}, total)
+}
+
+// Split splits d in n equal parts with places decimal places which sum exactly
+// to d. The remainder is distributed with RemainderLargest. It panics if n is
+// not positive or if d has more than places decimal places.
+//
+// Example:
+//
+//     parts := RequireFromStringWithName("total", "100").Split(3, 2)
+//     // parts: 33.34, 33.33, 33.33
+//     vars, formula := parts[2].Math()
+//     // vars:    "quoRem(2)(total / parts) = ?"
+//     // formula: "quoRem(2)(100 / 3) = 33.33"
+func (d Decimal) Split(n int, places int32) []Decimal {
+	return d.SplitWith(n, places, RemainderLargest)
+}
+
+// SplitWith splits d in n equal parts as Split() does, distributing the
+// remainder with r.
+func (d Decimal) SplitWith(n int, places int32, r Remainder) []Decimal {
+	if n <= 0 {
+		panic("tomath: cannot split in " + strconv.Itoa(n) + " parts")
+	}
+	if !d.decimal.Round(places).Equal(d.decimal) {
+		panic("tomath: cannot split " + d.String() + " to " + plural(int(places), "decimal place"))
+	}
+
+	parts := NewFromIntWithName("parts", int64(n))
+	return d.allocate(n, places, r, func(int) Decimal {
+		return d
+	}, parts)
+}
+
+// allocate returns the n parts share(i) / of rounded to places decimal places,
+// adjusted with r to sum to d.
+func (d Decimal) allocate(n int, places int32, r Remainder, share func(i int) Decimal, of Decimal) []Decimal {
+	parts := make([]Decimal, n)
+	// lost holds what the rounding of each part lost, signed for RemainderBank
+	// as its rounding may go either way.
+	lost := make([]decimal.Decimal, n)
+	switch r {
+	case RemainderLargest, RemainderFirst:
+		for i := range parts {
+			s := share(i)
+			q, rem := s.decimal.QuoRem(of.decimal, places)
+			parts[i] = newOp(q, OpQuoRem, []int32{places, 0}, s, of)
+			lost[i] = rem.Abs()
+		}
+	case RemainderBank:
+		c := Context{Precision: places, Rounding: RoundHalfEven}
+		for i := range parts {
+			s := share(i)
+			parts[i] = c.Div(s, of)
+			lost[i] = s.decimal.Sub(parts[i].decimal.Mul(of.decimal))
+		}
+	default:
+		panic("tomath: unknown remainder " + strconv.Itoa(int(r)))
+	}
+
+	left := d.decimal
+	for _, p := range parts {
+		left = left.Sub(p.decimal)
+	}
+	if left.IsZero() {
+		return parts
+	}
+
+	// The roundings lost less than one unit each, the remainder is a whole
+	// number of units given to fewer parts than those which lost anything.
+	unit := decimal.New(int64(left.Sign()), -places)
+	order := make([]int, 0, n)
+	switch r {
+	case RemainderBank:
+		for i := n - 1; i >= 0; i-- {
+			order = append(order, i)
+		}
+		sort.SliceStable(order, func(i, j int) bool {
+			a, b := parts[order[i]], parts[order[j]]
+			if c := lost[order[i]].Mul(unit).Cmp(lost[order[j]].Mul(unit)); c != 0 {
+				return c > 0
+			}
+			return odd(a.decimal, places) && !odd(b.decimal, places)
+		})
+	default:
+		for i := range parts {
+			if !lost[i].IsZero() {
+				order = append(order, i)
+			}
+		}
+		if r == RemainderLargest {
+			sort.SliceStable(order, func(i, j int) bool {
+				return lost[order[i]].GreaterThan(lost[order[j]])
+			})
+		}
+	}
+	for _, i := range order[:left.Div(unit).IntPart()] {
+		parts[i] = adjust(parts[i], unit)
+	}
+
+	return parts
+}
+
+// odd returns whether the last of the places decimal places of v is odd.
+func odd(v decimal.Decimal, places int32) bool {
+	return v.Shift(places).BigInt().Bit(0) == 1
+}
+
+// adjust returns part plus delta, recorded as an adjustment.
+func adjust(part Decimal, delta decimal.Decimal) Decimal {
+	if delta.Sign() < 0 {
+		return part.Sub(NewFromDecimalWithName(adjustment, delta.Neg()))
+	}
+	return part.Add(NewFromDecimalWithName(adjustment, delta))
+}
@@ -0,0 +1,113 @@
+package tomath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func partStrings(parts []Decimal) []string {
+	s := make([]string, len(parts))
+	for i, p := range parts {
+		s[i] = p.String()
+	}
+	return s
+}
+
+func TestAllocate(t *testing.T) {
+	total := RequireFromStringWithName("total", "0.10")
+	ratios := []Decimal{NewFromIntWithName("a", 3), NewFromIntWithName("b", 1), NewFromIntWithName("c", 3)}
+
+	tests := map[Remainder][]string{
+		RemainderLargest: {"0.04", "0.02", "0.04"},
+		RemainderFirst:   {"0.05", "0.01", "0.04"},
+		RemainderBank:    {"0.04", "0.02", "0.04"},
+	}
+
+	for r, want := range tests {
+		parts := total.AllocateWith(r, ratios...)
+		assert.Equal(t, want, partStrings(parts), r)
+		assert.True(t, Sum(parts[0], parts[1:]...).Equal(total), r)
+	}
+
+	assert.Equal(t, []string{"0.04", "0.02", "0.04"}, partStrings(total.Allocate(ratios...)))
+	assert.Equal(t, []string{"33.34", "0", "33.33", "33.33"}, partStrings(RequireFromString("100.00").Allocate(
+		NewFromInt(1), NewFromInt(0), NewFromInt(1), NewFromInt(1),
+	)))
+	assert.Equal(t, []string{"-34", "-33", "-33"}, partStrings(NewFromInt(-100).Allocate(NewFromInt(1), NewFromInt(1), NewFromInt(1))))
+	assert.Equal(t, []string{"25", "75"}, partStrings(NewFromInt(100).Allocate(NewFromFloat(0.25), NewFromFloat(0.75))))
+
+	// the rounding of every share is as far off, the unit goes to the odd part
+	assert.Equal(t, []string{"0.02", "0.06", "0.02"}, partStrings(total.AllocateWith(RemainderBank,
+		NewFromInt(1), NewFromInt(4), NewFromInt(1),
+	)))
+}
+
+func TestAllocateMath(t *testing.T) {
+	parts := RequireFromStringWithName("total", "100.00").Allocate(
+		NewFromIntWithName("alice", 1),
+		NewFromIntWithName("bob", 1),
+		NewFromIntWithName("carol", 1),
+	)
+
+	vars, formula := parts[0].SetName("alicePart").Math()
+	assert.Equal(t, "quoRem(2)(total * alice / sum(alice, bob, carol)) + adjustment = alicePart", vars)
+	assert.Equal(t, "quoRem(2)(100 * 1 / sum(1, 1, 1)) + 0.01 = 33.34", formula)
+
+	vars, formula = parts[1].Math()
+	assert.Equal(t, "quoRem(2)(total * bob / sum(alice, bob, carol)) = ?", vars)
+	assert.Equal(t, "quoRem(2)(100 * 1 / sum(1, 1, 1)) = 33.33", formula)
+
+	p, err := Parse(vars, map[string]Decimal{
+		"total": RequireFromString("100.00"),
+		"alice": NewFromInt(1),
+		"bob":   NewFromInt(1),
+		"carol": NewFromInt(1),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "33.33", p.String())
+}
+
+func TestSplit(t *testing.T) {
+	total := NewFromIntWithName("total", 100)
+
+	tests := map[Remainder][]string{
+		RemainderLargest: {"33.34", "33.33", "33.33"},
+		RemainderFirst:   {"33.34", "33.33", "33.33"},
+		RemainderBank:    {"33.33", "33.33", "33.34"},
+	}
+
+	for r, want := range tests {
+		assert.Equal(t, want, partStrings(total.SplitWith(3, 2, r)), r)
+	}
+
+	assert.Equal(t, []string{"16.67", "16.67", "16.67", "16.67", "16.66", "16.66"}, partStrings(total.Split(6, 2)))
+	assert.Equal(t, []string{"-16.67", "-16.67", "-16.67", "-16.67", "-16.66", "-16.66"}, partStrings(total.Neg().Split(6, 2)))
+	assert.Equal(t, []string{"16.67", "16.67", "16.67", "16.67", "16.66", "16.66"}, partStrings(total.SplitWith(6, 2, RemainderBank)))
+	assert.Equal(t, []string{"0.17", "0.17", "0.17", "0.17", "0.16", "0.16"}, partStrings(RequireFromString("1.00").SplitWith(6, 2, RemainderBank)))
+	assert.Equal(t, []string{"-0.17", "-0.17", "-0.17", "-0.17", "-0.16", "-0.16"}, partStrings(RequireFromString("-1.00").SplitWith(6, 2, RemainderBank)))
+	assert.Equal(t, []string{"0.02", "0.02", "0.03", "0.03"}, partStrings(RequireFromString("0.10").SplitWith(4, 2, RemainderBank)))
+	assert.Equal(t, []string{"0.08", "0.08", "0.08", "0.08", "0.09", "0.09"}, partStrings(RequireFromString("0.50").SplitWith(6, 2, RemainderBank)))
+	assert.Equal(t, []string{"100"}, partStrings(total.Split(1, 0)))
+
+	parts := total.Split(3, 2)
+	vars, formula := parts[0].Math()
+	assert.Equal(t, "quoRem(2)(total / parts) + adjustment = ?", vars)
+	assert.Equal(t, "quoRem(2)(100 / 3) + 0.01 = 33.34", formula)
+
+	vars, formula = total.SplitWith(3, 2, RemainderBank)[2].Math()
+	assert.Equal(t, "div[prec=2, round=halfEven](total, parts) + adjustment = ?", vars)
+	assert.Equal(t, "div[prec=2, round=halfEven](100, 3) + 0.01 = 33.34", formula)
+}
+
+func TestAllocatePanics(t *testing.T) {
+	d := NewFromInt(100)
+
+	assert.PanicsWithValue(t, "tomath: no allocation ratios", func() { d.Allocate() })
+	assert.PanicsWithValue(t, "tomath: negative allocation ratio -1", func() { d.Allocate(NewFromInt(2), NewFromInt(-1)) })
+	assert.PanicsWithValue(t, "tomath: allocation ratios sum to zero", func() { d.Allocate(NewFromInt(0), NewFromInt(0)) })
+	assert.PanicsWithValue(t, "tomath: unknown remainder 3", func() { d.AllocateWith(3, NewFromInt(1), NewFromInt(2)) })
+	assert.PanicsWithValue(t, "tomath: cannot split in 0 parts", func() { d.Split(0, 2) })
+	assert.PanicsWithValue(t, "tomath: cannot split 1.005 to 2 decimal places", func() { NewFromFloat(1.005).Split(2, 2) })
+}
@@ -0,0 +1,183 @@
+package tomath
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// contextParams is the number of parameters recording a Context.
+	contextParams = 3
+	contextOpen   = "["
+	contextClose  = "]"
+	// contextStart starts the context of a call in a formula.
+	contextStart = contextOpen + "prec="
+)
+
+// Context holds the precision and rounding of the operations which otherwise
+// round their result to decimal.DivisionPrecision digits after the decimal
+// point: Div(), the trigonometric and hyperbolic functions, Radians() and
+// Degrees(). Computations needing different precisions use their own Context
+// instead of changing the global decimal.DivisionPrecision.
+//
+// A Context is used for a single division with ctx.Div() or attached to a
+// decimal with WithContext(), in which case it rounds the operations of the
+// decimal and of every decimal computed from it, whichever operand it is
+// attached to. The operations on decimals with different contexts use the most
+// precise one. It is recorded in the formula of the operations it rounds.
+//
+// Example:
+//
+//     ctx := Context{Precision: 4, Rounding: RoundHalfEven}
+//     vars, formula := ctx.Div(NewFromFloatWithName("var1", 2), NewFromFloatWithName("var2", 3)).
+//         SetName("var3").
+//         Math()
+//     // vars:    "div[prec=4, round=halfEven](var1, var2) = var3"
+//     // formula: "div[prec=4, round=halfEven](2, 3) = 0.6667"
+//
+// The zero value rounds half away from zero to integers.
+type Context struct {
+	// Precision is the number of digits after the decimal point of the results.
+	Precision int32
+	// Rounding selects how the results are rounded.
+	Rounding RoundMode
+	// MaxDigits limits the number of significant digits of the results when
+	// positive, rounding large results to fewer digits after the decimal point.
+	MaxDigits int32
+}
+
+// Div returns d / d2 rounded by the context.
+func (c Context) Div(d, d2 Decimal) Decimal {
+	return newOp(c.quo(d.decimal, d2.decimal), OpDiv, c.params(), d, d2)
+}
+
+// String returns the context as written in a formula, ex: "prec=16" or
+// "prec=4, round=halfEven, digits=10".
+func (c Context) String() string {
+	s := "prec=" + strconv.Itoa(int(c.Precision))
+	if c.Rounding != RoundHalfUp {
+		s += comma + "round=" + c.Rounding.String()
+	}
+	if c.MaxDigits > 0 {
+		s += comma + "digits=" + strconv.Itoa(int(c.MaxDigits))
+	}
+	return s
+}
+
+// WithContext returns d with the context c attached. The divisions and
+// functions of d, and of the decimals computed from d, are rounded by c.
+func (d Decimal) WithContext(c Context) Decimal {
+	d.context = &c
+	return d
+}
+
+// Context returns the context attached to d, if any.
+func (d Decimal) Context() (Context, bool) {
+	if d.context == nil {
+		return Context{}, false
+	}
+	return *d.context, true
+}
+
+// roundingContext returns the context rounding an operation on operands and
+// the parameters recording it, which are nil when no operand has a context.
+func roundingContext(operands ...Decimal) (Context, []int32) {
+	c := operandsContext(operands...)
+	if c == nil {
+		return Context{Precision: int32(decimal.DivisionPrecision)}, nil
+	}
+	return *c, c.params()
+}
+
+// operandsContext returns the most precise context of operands, or nil when
+// none has a context. The choice does not depend on the order of operands.
+func operandsContext(operands ...Decimal) *Context {
+	var c *Context
+	for _, o := range operands {
+		if o.context != nil && (c == nil || o.context.finer(*c)) {
+			c = o.context
+		}
+	}
+	return c
+}
+
+// finer returns whether c is more precise than c2: it has more digits after
+// the decimal point, or as many and more significant digits. Contexts as
+// precise are ordered by their rounding mode.
+func (c Context) finer(c2 Context) bool {
+	if c.Precision != c2.Precision {
+		return c.Precision > c2.Precision
+	}
+	if m, m2 := c.maxDigits(), c2.maxDigits(); m != m2 {
+		return m > m2
+	}
+	return c.Rounding < c2.Rounding
+}
+
+// maxDigits returns the significant digits of the results, MaxInt32 when they
+// are not limited.
+func (c Context) maxDigits() int32 {
+	if c.MaxDigits <= 0 {
+		return math.MaxInt32
+	}
+	return c.MaxDigits
+}
+
+// unary returns op applied to d using f, which computes the result to about wp
+// digits after the decimal point, rounded by the context of d.
+func (d Decimal) unary(op Op, f func(x decimal.Decimal, wp int32) decimal.Decimal) Decimal {
+	c, params := roundingContext(d)
+	return newOp(c.round(f(d.decimal, workPrec(c.Precision))), op, params, d)
+}
+
+func (c Context) params() []int32 {
+	return []int32{c.Precision, int32(c.Rounding), c.MaxDigits}
+}
+
+// contextOf returns the context recorded in the parameters of op, if any.
+func contextOf(op Op, params []int32) (Context, bool) {
+	if !takesContext(op) || len(params) != contextParams {
+		return Context{}, false
+	}
+	return Context{Precision: params[0], Rounding: RoundMode(params[1]), MaxDigits: params[2]}, true
+}
+
+// takesContext returns whether op is rounded by a Context.
+func takesContext(op Op) bool {
+	switch op {
+	case OpDiv, OpAtan, OpSin, OpCos, OpTan, OpAsin, OpAcos, OpAtan2,
+		OpSinh, OpCosh, OpTanh, OpAsinh, OpAcosh, OpAtanh, OpRadians, OpDegrees:
+		return true
+	}
+	return false
+}
+
+// round rounds v according to the context.
+func (c Context) round(v decimal.Decimal) decimal.Decimal {
+	return c.quo(v, one)
+}
+
+// quo returns x / y rounded according to the context.
+func (c Context) quo(x, y decimal.Decimal) decimal.Decimal {
+	places := c.Precision
+	if c.MaxDigits <= 0 {
+		return c.Rounding.quo(x, y, places)
+	}
+
+	q, _ := x.QuoRem(y, places)
+	m := magnitude(q)
+	if p := c.MaxDigits - m; p < places {
+		places = p
+	}
+
+	v := c.Rounding.quo(x, y, places)
+	if places < c.Precision && magnitude(v) > m {
+		// Rounding carried into a new digit, ex: 9.99 to 10.0, the result is a
+		// power of ten rounded exactly.
+		v = v.Round(places - 1)
+	}
+
+	return v
+}
@@ -0,0 +1,143 @@
+package tomath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextDiv(t *testing.T) {
+	ctx := Context{Precision: 4, Rounding: RoundHalfEven}
+	d := ctx.Div(NewFromFloatWithName("var1", 2), NewFromFloatWithName("var2", 3)).SetName("var3")
+
+	vars, formula := d.Math()
+	assert.Equal(t, "div[prec=4, round=halfEven](var1, var2) = var3", vars)
+	assert.Equal(t, "div[prec=4, round=halfEven](2, 3) = 0.6667", formula)
+	_, ok := d.Context()
+	assert.False(t, ok)
+
+	steps := d.Steps()
+	require.Len(t, steps, 1)
+	assert.Equal(t, "div[prec=4, round=halfEven](2, 3) = 0.6667", steps[0].String())
+	assert.Equal(t, "var1 (2), divided by var2 (3), to 4 decimal places rounding half to even", d.Explain())
+}
+
+func TestContextRounding(t *testing.T) {
+	tests := []struct {
+		mode          RoundMode
+		pos, neg, odd string
+	}{
+		{RoundHalfUp, "0.13", "-0.13", "0.38"},
+		{RoundHalfEven, "0.12", "-0.12", "0.38"},
+		{RoundDown, "0.12", "-0.12", "0.37"},
+		{RoundCeiling, "0.13", "-0.12", "0.38"},
+		{RoundFloor, "0.12", "-0.13", "0.37"},
+	}
+
+	one, eight := NewFromInt(1), NewFromInt(8)
+	for _, test := range tests {
+		ctx := Context{Precision: 2, Rounding: test.mode}
+		assert.Equal(t, test.pos, ctx.Div(one, eight).String(), test.mode.String())
+		assert.Equal(t, test.neg, ctx.Div(one.Neg(), eight).String(), test.mode.String())
+		assert.Equal(t, test.odd, ctx.Div(NewFromInt(3), eight).String(), test.mode.String())
+	}
+
+	assert.Equal(t, "0.3333333333", Context{Precision: 10}.Div(one, NewFromInt(3)).String())
+	assert.Equal(t, "-30", Context{Precision: -1}.Div(NewFromInt(-100), NewFromInt(3)).String())
+	assert.Panics(t, func() { Context{Rounding: 42}.Div(one, NewFromInt(3)) })
+}
+
+func TestContextMaxDigits(t *testing.T) {
+	ctx := Context{Precision: 4, MaxDigits: 6}
+	tests := map[string]Decimal{
+		"66666.7":  ctx.Div(NewFromInt(200000), NewFromInt(3)),
+		"0.3333":   ctx.Div(NewFromInt(1), NewFromInt(3)),
+		"66666700": ctx.Div(NewFromInt(200000000), NewFromInt(3)),
+		"10000":    Context{Precision: 2, MaxDigits: 5}.Div(RequireFromString("9999.96"), NewFromInt(1)),
+	}
+
+	for want, d := range tests {
+		assert.Equal(t, want, d.String())
+	}
+
+	vars, _ := ctx.Div(NewFromIntWithName("var1", 1), NewFromIntWithName("var2", 3)).Math()
+	assert.Equal(t, "div[prec=4, digits=6](var1, var2) = ?", vars)
+}
+
+func TestWithContext(t *testing.T) {
+	ctx := Context{Precision: 3}
+	var1 := NewFromIntWithName("var1", 1).WithContext(ctx)
+	var2 := NewFromIntWithName("var2", 3)
+
+	d := var1.Div(var2).Add(NewFromIntWithName("var3", 2)).Div(var2).SetName("var4")
+	vars, formula := d.Math()
+	assert.Equal(t, "div[prec=3](div[prec=3](var1, var2) + var3, var2) = var4", vars)
+	assert.Equal(t, "div[prec=3](div[prec=3](1, 3) + 2, 3) = 0.778", formula)
+
+	c, ok := d.Context()
+	require.True(t, ok)
+	assert.Equal(t, ctx, c)
+
+	vars, _ = var2.Div(var1).Math()
+	assert.Equal(t, "div[prec=3](var2, var1) = ?", vars)
+
+	rebound := d.Rebind(map[string]Decimal{"var1": NewFromInt(2)})
+	assert.Equal(t, "0.889", rebound.String())
+	_, formula = rebound.Math()
+	assert.Equal(t, "div[prec=3](div[prec=3](2, 3) + 2, 3) = 0.889", formula)
+}
+
+func TestWithContextRightOperand(t *testing.T) {
+	ctx := Context{Precision: 3}
+	a := NewFromIntWithName("a", 1).WithContext(ctx)
+	b := NewFromIntWithName("b", 1)
+	c := NewFromIntWithName("c", 3)
+
+	d := b.Add(a).Div(c)
+	vars, formula := d.Math()
+	assert.Equal(t, "div[prec=3](b + a, c) = ?", vars)
+	assert.Equal(t, "div[prec=3](1 + 1, 3) = 0.667", formula)
+	got, ok := d.Context()
+	require.True(t, ok)
+	assert.Equal(t, ctx, got)
+
+	vars, _ = c.Atan2(a).Math()
+	assert.Equal(t, "atan2[prec=3](c, a) = ?", vars)
+
+	// the most precise context is used whatever the order of the operands
+	fine := Context{Precision: 5, Rounding: RoundHalfEven}
+	x := NewFromIntWithName("x", 2).WithContext(fine)
+	for _, d := range []Decimal{a.Add(x).Div(c), x.Add(a).Div(c), c.Mul(a).Div(x), Sum(a, c, x).Div(c)} {
+		got, ok := d.Context()
+		require.True(t, ok)
+		assert.Equal(t, fine, got)
+		assert.Contains(t, d.Expr().String(), "div[prec=5, round=halfEven](")
+	}
+
+	assert.True(t, Context{Precision: 2}.finer(Context{Precision: 1, MaxDigits: 10}))
+	assert.True(t, Context{Precision: 2}.finer(Context{Precision: 2, MaxDigits: 10}))
+	assert.True(t, Context{Precision: 2, MaxDigits: 11}.finer(Context{Precision: 2, MaxDigits: 10}))
+	assert.False(t, Context{Precision: 2, Rounding: RoundDown}.finer(Context{Precision: 2}))
+}
+
+func TestContextFunctions(t *testing.T) {
+	ctx := Context{Precision: 30}
+	tests := map[string]Decimal{
+		"0.78539816339744830961566084582":  NewFromInt(1).WithContext(ctx).Atan(),
+		"0.84147098480789650665250232163":  NewFromInt(1).WithContext(ctx).Sin(),
+		"0.540302305868139717400936607443": NewFromInt(1).WithContext(ctx).Cos(),
+		"1.557407724654902230506974807458": NewFromInt(1).WithContext(ctx).Tan(),
+		"0.523598775598298873077107230547": NewFromFloat(0.5).WithContext(ctx).Asin(),
+		"3.141592653589793238462643383279502884197169399375105820974944592307816406286208998628034825342117067982148086513282306647": NewFromInt(180).WithContext(Context{Precision: 120}).Radians(),
+		"0.8414709848": NewFromFloat(1 + 2*3.141592653589793).WithContext(Context{Precision: 10}).Sin(),
+	}
+
+	for want, d := range tests {
+		assert.Equal(t, want, d.String())
+	}
+
+	vars, formula := NewFromIntWithName("var1", 1).WithContext(Context{Precision: 2, Rounding: RoundDown}).Atan2(NewFromIntWithName("var2", 1)).Math()
+	assert.Equal(t, "atan2[prec=2, round=down](var1, var2) = ?", vars)
+	assert.Equal(t, "atan2[prec=2, round=down](1, 1) = 0.78", formula)
+}
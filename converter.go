@@ -0,0 +1,264 @@
+package tomath
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// rateDate is the layout of the dates of the rates.
+const rateDate = "2006-01-02"
+
+// Rate is the exchange rate of a currency pair on a date: one unit of From is
+// worth Value units of To.
+type Rate struct {
+	From  string
+	To    string
+	Date  time.Time
+	Value decimal.Decimal
+}
+
+// Name returns the name of the rate in a formula, ex: "rate[EURUSD@2026-10-15]".
+func (r Rate) Name() string {
+	return "rate[" + r.From + r.To + "@" + r.Date.Format(rateDate) + "]"
+}
+
+// Decimal returns the value of the rate named by Name().
+func (r Rate) Decimal() Decimal {
+	return NewFromDecimalWithName(r.Name(), r.Value)
+}
+
+// RatesFromMap returns the rates on date of the pairs of currency codes mapped
+// to their value, ex: {"EURUSD": 1.0712}.
+func RatesFromMap(date time.Time, rates map[string]decimal.Decimal) ([]Rate, error) {
+	var rs []Rate
+	for pair, value := range rates {
+		r, err := newRate(pair, date, value)
+		if err != nil {
+			return nil, err
+		}
+		rs = append(rs, r)
+	}
+	return rs, nil
+}
+
+// ReadRatesCSV reads rates from CSV records holding the pair of currency
+// codes, the date and the value of each rate, ex: "EURUSD,2026-10-15,1.0712". A
+// header record starting with "pair" is skipped.
+func ReadRatesCSV(r io.Reader) ([]Rate, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = 3
+	cr.TrimLeadingSpace = true
+
+	var rs []Rate
+	for first := true; ; first = false {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return rs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if first && record[0] == "pair" {
+			continue
+		}
+
+		rate, err := parseRate(record[0], record[1], record[2])
+		if err != nil {
+			return nil, err
+		}
+		rs = append(rs, rate)
+	}
+}
+
+// ReadRatesJSON reads rates from a JSON array of objects holding the pair of
+// currency codes, the date and the value of each rate, ex:
+// [{"pair": "EURUSD", "date": "2026-10-15", "rate": "1.0712"}].
+func ReadRatesJSON(r io.Reader) ([]Rate, error) {
+	var v []struct {
+		Pair string          `json:"pair"`
+		Date string          `json:"date"`
+		Rate decimal.Decimal `json:"rate"`
+	}
+	if err := json.NewDecoder(r).Decode(&v); err != nil {
+		return nil, err
+	}
+
+	rs := make([]Rate, len(v))
+	for i, rv := range v {
+		date, err := time.Parse(rateDate, rv.Date)
+		if err != nil {
+			return nil, err
+		}
+		if rs[i], err = newRate(rv.Pair, date, rv.Rate); err != nil {
+			return nil, err
+		}
+	}
+	return rs, nil
+}
+
+func parseRate(pair, date, value string) (Rate, error) {
+	d, err := time.Parse(rateDate, date)
+	if err != nil {
+		return Rate{}, err
+	}
+	v, err := decimal.NewFromString(value)
+	if err != nil {
+		return Rate{}, err
+	}
+	return newRate(pair, d, v)
+}
+
+func newRate(pair string, date time.Time, value decimal.Decimal) (Rate, error) {
+	if len(pair) != 6 {
+		return Rate{}, errors.New("tomath: invalid currency pair " + pair)
+	}
+	return Rate{From: pair[:3], To: pair[3:], Date: date, Value: value}, nil
+}
+
+// Converter converts amounts between currencies with a table of rates. The
+// rates used are recorded in the formula of the results.
+//
+// Example:
+//
+//     rates, _ := RatesFromMap(date, map[string]decimal.Decimal{"EURUSD": decimal.RequireFromString("1.0712")})
+//     c, _ := NewConverter("", rates...)
+//     amount, _ := NewMoney("amount", "10", "EUR")
+//     usd, _ := c.Convert(amount, "USD")
+//     usd.Math()
+//     // output: "round(2)(amount (EUR 10.00) * rate[EURUSD@2026-10-15] (1.0712)) = amountUSD (USD 10.71)"
+//
+// A pair missing from the table is converted with the inverse of the rate of
+// the reversed pair, ex: "amount / rate[USDEUR@2026-10-15]", or through the
+// base currency, ex: "amount * rate[EURUSD@2026-10-15] / rate[GBPUSD@2026-10-15]".
+type Converter struct {
+	base  string
+	rates map[string]Rate
+}
+
+// NewConverter returns a converter using the latest of the given rates of each
+// pair, converting through the base currency the pairs missing from rates. base
+// may be empty. It returns an error if a rate is not positive or if two rates
+// of a pair have the same date.
+func NewConverter(base string, rates ...Rate) (*Converter, error) {
+	c := &Converter{base: base, rates: map[string]Rate{}}
+	for _, r := range rates {
+		if r.Value.Sign() <= 0 {
+			return nil, errors.New("tomath: rate " + r.Name() + " is not positive")
+		}
+
+		pair := r.From + r.To
+		if prev, ok := c.rates[pair]; ok {
+			if prev.Date.Equal(r.Date) {
+				return nil, errors.New("tomath: duplicate rate " + r.Name())
+			}
+			if prev.Date.After(r.Date) {
+				continue
+			}
+		}
+		c.rates[pair] = r
+	}
+	return c, nil
+}
+
+// Rate returns the rate of the pair of currency codes, ex: "EURUSD", if any.
+func (c *Converter) Rate(pair string) (Rate, bool) {
+	r, ok := c.rates[pair]
+	return r, ok
+}
+
+// Convert returns m in the currency with the code to, rounded once to its minor
+// units in the rounding mode of m. The result is named after m followed by the
+// code, ex: "amountUSD".
+func (c *Converter) Convert(m Money, to string) (Money, error) {
+	if m.currency.Code == to {
+		return m, nil
+	}
+	currency, ok := LookupCurrency(to)
+	if !ok {
+		return Money{}, errors.New("tomath: unknown currency " + to)
+	}
+
+	v, by, inverse, err := c.convert(m.amount, m.currency.Code, to)
+	if err != nil {
+		return Money{}, err
+	}
+
+	result := Money{currency: currency, rounding: m.rounding}
+	if inverse {
+		ctx := Context{Precision: currency.MinorUnits, Rounding: m.rounding}
+		result.amount = ctx.Div(v, by)
+	} else {
+		result = result.with(v)
+	}
+
+	if m.amount.name != "" {
+		result = result.SetName(m.amount.name + to)
+	}
+	return result, nil
+}
+
+// ConvertDecimal returns the amount d in the currency with the code from
+// converted to the currency with the code to. Inverse rates divide d as Div()
+// does. The result is named after d followed by the code, ex: "amountUSD".
+func (c *Converter) ConvertDecimal(d Decimal, from, to string) (Decimal, error) {
+	if from == to {
+		return d, nil
+	}
+
+	v, by, inverse, err := c.convert(d, from, to)
+	if err != nil {
+		return Decimal{}, err
+	}
+	if inverse {
+		v = v.Div(by)
+	}
+
+	if d.name != "" {
+		v = v.SetName(d.name + to)
+	}
+	return v, nil
+}
+
+// convert returns d multiplied by the rates converting from one currency to
+// the other and, when inverse is set, the product of the inverse rates it must
+// be divided by.
+func (c *Converter) convert(d Decimal, from, to string) (v, by Decimal, inverse bool, err error) {
+	legs := [][2]string{{from, to}}
+	if _, _, ok := c.leg(from, to); !ok && c.base != "" && from != c.base && to != c.base {
+		legs = [][2]string{{from, c.base}, {c.base, to}}
+	}
+
+	for _, l := range legs {
+		r, inv, ok := c.leg(l[0], l[1])
+		if !ok {
+			return Decimal{}, Decimal{}, false, errors.New("tomath: no rate from " + from + " to " + to)
+		}
+		switch {
+		case !inv:
+			d = d.Mul(r)
+		case inverse:
+			by = by.Mul(r)
+		default:
+			by, inverse = r, true
+		}
+	}
+
+	return d, by, inverse, nil
+}
+
+// leg returns the rate converting from one currency to the other directly or
+// as the inverse of the rate of the reversed pair.
+func (c *Converter) leg(from, to string) (Decimal, bool, bool) {
+	if r, ok := c.rates[from+to]; ok {
+		return r.Decimal(), false, true
+	}
+	if r, ok := c.rates[to+from]; ok {
+		return r.Decimal(), true, true
+	}
+	return Decimal{}, false, false
+}
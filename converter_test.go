@@ -0,0 +1,153 @@
+package tomath
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var rateDay = time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+
+func testConverter(t *testing.T, base string) *Converter {
+	t.Helper()
+	rates, err := RatesFromMap(rateDay, map[string]decimal.Decimal{
+		"EURUSD": decimal.RequireFromString("1.0712"),
+		"GBPUSD": decimal.RequireFromString("1.2650"),
+		"USDJPY": decimal.RequireFromString("150.25"),
+	})
+	require.NoError(t, err)
+	c, err := NewConverter(base, rates...)
+	require.NoError(t, err)
+	return c
+}
+
+func TestConvert(t *testing.T) {
+	c := testConverter(t, "USD")
+
+	tests := []struct {
+		value, from, to string
+		want            string
+	}{
+		{"10", "EUR", "USD", "round(2)(amount (EUR 10.00) * rate[EURUSD@2026-10-15] (1.0712)) = amountUSD (USD 10.71)"},
+		{"10", "USD", "EUR", "div[prec=2](amount (USD 10.00), rate[EURUSD@2026-10-15] (1.0712)) = amountEUR (EUR 9.34)"},
+		{"10", "EUR", "GBP", "div[prec=2](amount (EUR 10.00) * rate[EURUSD@2026-10-15] (1.0712), rate[GBPUSD@2026-10-15] (1.265)) = amountGBP (GBP 8.47)"},
+		{"10", "EUR", "JPY", "round(0)(amount (EUR 10.00) * rate[EURUSD@2026-10-15] (1.0712) * rate[USDJPY@2026-10-15] (150.25)) = amountJPY (JPY 1609)"},
+		{"1000", "JPY", "GBP", "div[prec=2](amount (JPY 1000), rate[USDJPY@2026-10-15] (150.25) * rate[GBPUSD@2026-10-15] (1.265)) = amountGBP (GBP 5.26)"},
+		{"10", "USD", "USD", "amount (USD 10.00) = amount (USD 10.00)"},
+	}
+
+	for _, tt := range tests {
+		m, err := NewMoney("amount", tt.value, tt.from)
+		require.NoError(t, err)
+		converted, err := c.Convert(m, tt.to)
+		require.NoError(t, err, tt.want)
+		assert.Equal(t, tt.want, converted.Math())
+		assert.Equal(t, tt.to, converted.Currency().Code)
+	}
+
+	m, err := NewMoney("amount", "10", "USD")
+	require.NoError(t, err)
+	converted, err := c.Convert(m.WithRounding(RoundUp), "EUR")
+	require.NoError(t, err)
+	assert.Equal(t, "div[prec=2, round=up](amount (USD 10.00), rate[EURUSD@2026-10-15] (1.0712)) = amountEUR (EUR 9.34)", converted.Math())
+}
+
+func TestConvertErrors(t *testing.T) {
+	m, err := NewMoney("amount", "10", "EUR")
+	require.NoError(t, err)
+
+	_, err = testConverter(t, "").Convert(m, "GBP")
+	assert.EqualError(t, err, "tomath: no rate from EUR to GBP")
+
+	_, err = testConverter(t, "USD").Convert(m, "CHF")
+	assert.EqualError(t, err, "tomath: no rate from EUR to CHF")
+
+	_, err = testConverter(t, "USD").Convert(m, "ABC")
+	assert.EqualError(t, err, "tomath: unknown currency ABC")
+
+	_, err = NewConverter("", Rate{From: "EUR", To: "USD", Date: rateDay})
+	assert.EqualError(t, err, "tomath: rate rate[EURUSD@2026-10-15] is not positive")
+
+	r := Rate{From: "EUR", To: "USD", Date: rateDay, Value: decimal.RequireFromString("1.07")}
+	_, err = NewConverter("", r, r)
+	assert.EqualError(t, err, "tomath: duplicate rate rate[EURUSD@2026-10-15]")
+
+	_, err = RatesFromMap(rateDay, map[string]decimal.Decimal{"EUR": decimal.New(1, 0)})
+	assert.EqualError(t, err, "tomath: invalid currency pair EUR")
+}
+
+func TestConvertDecimal(t *testing.T) {
+	c := testConverter(t, "USD")
+
+	d, err := c.ConvertDecimal(NewFromIntWithName("amount", 10), "EUR", "USD")
+	require.NoError(t, err)
+	vars, formula := d.Math()
+	assert.Equal(t, "amount * rate[EURUSD@2026-10-15] = amountUSD", vars)
+	assert.Equal(t, "10 * 1.0712 = 10.712", formula)
+
+	d, err = c.ConvertDecimal(NewFromInt(10), "USD", "EUR")
+	require.NoError(t, err)
+	vars, formula = d.Math()
+	assert.Equal(t, "? / rate[EURUSD@2026-10-15] = ?", vars)
+	assert.Equal(t, "10 / 1.0712 = 9.3353248693054518", formula)
+
+	p, err := Parse("amount * rate[EURUSD@2026-10-15]", map[string]Decimal{
+		"amount":                  NewFromInt(10),
+		"rate[EURUSD@2026-10-15]": NewFromFloat(1.0712),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "10.712", p.String())
+
+	_, err = c.ConvertDecimal(NewFromInt(10), "EUR", "CHF")
+	assert.EqualError(t, err, "tomath: no rate from EUR to CHF")
+}
+
+func TestConverterLatestRate(t *testing.T) {
+	older := Rate{From: "EUR", To: "USD", Date: rateDay.AddDate(0, 0, -1), Value: decimal.RequireFromString("1.07")}
+	latest := Rate{From: "EUR", To: "USD", Date: rateDay, Value: decimal.RequireFromString("1.0712")}
+
+	for _, rates := range [][]Rate{{older, latest}, {latest, older}} {
+		c, err := NewConverter("", rates...)
+		require.NoError(t, err)
+		r, ok := c.Rate("EURUSD")
+		assert.True(t, ok)
+		assert.Equal(t, latest, r)
+	}
+}
+
+func TestReadRates(t *testing.T) {
+	want := []Rate{
+		{From: "EUR", To: "USD", Date: rateDay, Value: decimal.RequireFromString("1.0712")},
+		{From: "GBP", To: "USD", Date: rateDay, Value: decimal.RequireFromString("1.265")},
+	}
+
+	rates, err := ReadRatesCSV(strings.NewReader("pair,date,rate\nEURUSD,2026-10-15,1.0712\nGBPUSD, 2026-10-15, 1.265\n"))
+	require.NoError(t, err)
+	assert.Equal(t, want, rates)
+
+	rates, err = ReadRatesCSV(strings.NewReader("EURUSD,2026-10-15,1.0712\nGBPUSD,2026-10-15,1.265\n"))
+	require.NoError(t, err)
+	assert.Equal(t, want, rates)
+
+	rates, err = ReadRatesJSON(strings.NewReader(`[
+		{"pair": "EURUSD", "date": "2026-10-15", "rate": "1.0712"},
+		{"pair": "GBPUSD", "date": "2026-10-15", "rate": 1.265}
+	]`))
+	require.NoError(t, err)
+	assert.Equal(t, want, rates)
+
+	errs := map[string]error{}
+	_, errs["csv date"] = ReadRatesCSV(strings.NewReader("EURUSD,15/10/2026,1.0712\n"))
+	_, errs["csv value"] = ReadRatesCSV(strings.NewReader("EURUSD,2026-10-15,abc\n"))
+	_, errs["csv fields"] = ReadRatesCSV(strings.NewReader("EURUSD,2026-10-15\n"))
+	_, errs["csv pair"] = ReadRatesCSV(strings.NewReader("EURO,2026-10-15,1\n"))
+	_, errs["json"] = ReadRatesJSON(strings.NewReader(`{"pair": "EURUSD"}`))
+	_, errs["json date"] = ReadRatesJSON(strings.NewReader(`[{"pair": "EURUSD", "date": "", "rate": 1}]`))
+	for name, err := range errs {
+		assert.Error(t, err, name)
+	}
+}
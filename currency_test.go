@@ -0,0 +1,28 @@
+package tomath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookupCurrency(t *testing.T) {
+	tests := map[string]int32{
+		"USD": 2,
+		"EUR": 2,
+		"JPY": 0,
+		"KWD": 3,
+		"CLF": 4,
+	}
+
+	for code, minor := range tests {
+		c, ok := LookupCurrency(code)
+		assert.True(t, ok, code)
+		assert.Equal(t, Currency{Code: code, MinorUnits: minor}, c)
+	}
+
+	for _, code := range []string{"", "usd", "XAU", "ABC"} {
+		_, ok := LookupCurrency(code)
+		assert.False(t, ok, code)
+	}
+}
@@ -0,0 +1,166 @@
+package tomath
+
+import (
+	"math/big"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	one         = decimal.New(1, 0)
+	two         = decimal.New(2, 0)
+	half        = decimal.New(5, -1)
+	log10OfE    = decimal.New(4343, -4)
+	nearOne     = decimal.New(101, -2)
+	lnGuard     = int32(40)
+	minWorkPrec = int32(10)
+)
+
+// sqrtDecimal returns the square root of x rounded to precision digits after
+// the decimal point. It panics if x is negative.
+func sqrtDecimal(x decimal.Decimal, precision int32) decimal.Decimal {
+	if x.Sign() < 0 {
+		panic("tomath: square root of negative number")
+	}
+
+	// The square root is computed exactly to two more digits and truncated,
+	// which cannot move it across the half the final round is deciding on.
+	// Truncating x beforehand does not change the truncated square root.
+	scale := precision + 2
+	n := new(big.Int).Set(x.Coefficient())
+	if k := x.Exponent() + 2*scale; k >= 0 {
+		n.Mul(n, pow10(k))
+	} else {
+		n.Quo(n, pow10(-k))
+	}
+
+	return decimal.NewFromBigInt(n.Sqrt(n), -scale).Round(precision)
+}
+
+// expDecimal returns e to the power x rounded to precision digits after the
+// decimal point.
+func expDecimal(x decimal.Decimal, precision int32) decimal.Decimal {
+	// exp(x) = exp(x / 2^k)^(2^k) with x / 2^k below 1 for the Taylor series to
+	// converge quickly.
+	y := x.Abs()
+	k := int32(0)
+	for y.Cmp(one) > 0 {
+		y = y.Mul(half)
+		k++
+	}
+
+	// Squaring k times loses about k * log10(2) digits and the integer digits
+	// of the result are lost to the working precision.
+	wp := precision + k + 1 + int32(x.Abs().Mul(log10OfE).IntPart()) + minWorkPrec
+	if wp < minWorkPrec {
+		wp = minWorkPrec
+	}
+
+	sum, term := one, one
+	for n := int64(1); ; n++ {
+		term = term.Mul(y).DivRound(decimal.New(n, 0), wp)
+		if term.IsZero() {
+			break
+		}
+		sum = sum.Add(term)
+	}
+
+	for i := int32(0); i < k; i++ {
+		sum = sum.Mul(sum).Round(wp)
+	}
+
+	if x.Sign() < 0 {
+		sum = one.DivRound(sum, wp)
+	}
+
+	return sum.Round(precision)
+}
+
+// lnDecimal returns the natural logarithm of x rounded to precision digits
+// after the decimal point. It panics if x is not positive.
+func lnDecimal(x decimal.Decimal, precision int32) decimal.Decimal {
+	if x.Sign() <= 0 {
+		panic("tomath: logarithm of non-positive number")
+	}
+	return lnAt(x, workPrec(precision)).Round(precision)
+}
+
+// logDecimal returns the logarithm of x in base rounded to precision digits
+// after the decimal point. It panics if x or base is not positive or base is 1.
+func logDecimal(x, base decimal.Decimal, precision int32) decimal.Decimal {
+	if x.Sign() <= 0 {
+		panic("tomath: logarithm of non-positive number")
+	}
+	if base.Sign() <= 0 || base.Equal(one) {
+		panic("tomath: invalid logarithm base " + base.String())
+	}
+
+	wp := workPrec(precision)
+	lx, lb := lnAt(x, wp), lnAt(base, wp)
+
+	// A large quotient or a logarithm of the base close to 0 amplify the error
+	// of the logarithms, in which case they are computed again more precisely.
+	extra := magnitude(lx) - magnitude(lb)
+	if m := -magnitude(lb); m > extra {
+		extra = m
+	}
+	if extra > 0 {
+		wp += extra
+		lx, lb = lnAt(x, wp), lnAt(base, wp)
+	}
+
+	return lx.DivRound(lb, wp).Round(precision)
+}
+
+// lnAt returns the natural logarithm of the positive x to about wp digits after
+// the decimal point.
+func lnAt(x decimal.Decimal, wp int32) decimal.Decimal {
+	if x.Cmp(one) < 0 {
+		return lnAt(one.DivRound(x, wp+lnGuard), wp).Neg()
+	}
+
+	// ln(x) = 2^k * ln(x^(1/2^k)) with x^(1/2^k) close enough to 1 for the
+	// series ln(y) = 2 * atanh((y - 1) / (y + 1)) to converge quickly. The
+	// guard digits absorb the error multiplied by 2^k.
+	p := wp + lnGuard
+	y := x
+	k := 0
+	for y.Cmp(nearOne) > 0 {
+		y = sqrtDecimal(y, p)
+		k++
+	}
+
+	z := y.Sub(one).DivRound(y.Add(one), p)
+	z2 := z.Mul(z).Round(p)
+	sum, term := z, z
+	for n := int64(3); ; n += 2 {
+		term = term.Mul(z2).Round(p)
+		t := term.DivRound(decimal.New(n, 0), p)
+		if t.IsZero() {
+			break
+		}
+		sum = sum.Add(t)
+	}
+
+	return sum.Mul(two).Mul(decimal.NewFromBigInt(new(big.Int).Lsh(big.NewInt(1), uint(k)), 0)).Round(wp)
+}
+
+// workPrec returns the working precision of a logarithm rounded to precision.
+func workPrec(precision int32) int32 {
+	if precision < 0 {
+		return minWorkPrec
+	}
+	return precision + minWorkPrec
+}
+
+// magnitude returns m such that 10^(m-1) <= |v| < 10^m.
+func magnitude(v decimal.Decimal) int32 {
+	if v.IsZero() {
+		return 0
+	}
+	return int32(len(new(big.Int).Abs(v.Coefficient()).String())) + v.Exponent()
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
@@ -0,0 +1,114 @@
+package tomath
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSqrt(t *testing.T) {
+	tests := map[string]Decimal{
+		"1.41421356237309504880168872421": NewFromInt(2).Sqrt(30),
+		"4":                               NewFromInt(16).Sqrt(2),
+		"0":                               NewFromInt(0).Sqrt(5),
+		"0.01":                            NewFromFloat(0.0001).Sqrt(4),
+		"0.0316":                          NewFromFloat(0.001).Sqrt(4),
+		"110":                             NewFromInt(12345).Sqrt(-1),
+		"1.5":                             NewFromFloat(2.25).Sqrt(1),
+	}
+
+	for want, d := range tests {
+		assert.Equal(t, want, d.String())
+	}
+
+	assert.PanicsWithValue(t, "tomath: square root of negative number", func() { NewFromInt(-1).Sqrt(2) })
+}
+
+func TestExp(t *testing.T) {
+	tests := map[string]Decimal{
+		"2.718281828459045235360287471353": NewFromInt(1).Exp(30),
+		"0.3678794411714423216":            NewFromInt(-1).Exp(20),
+		"22026.46579":                      NewFromInt(10).Exp(5),
+		"1":                                NewFromInt(0).Exp(5),
+		"1.051271096376024":                NewFromFloat(0.05).Exp(16),
+	}
+
+	for want, d := range tests {
+		assert.Equal(t, want, d.String())
+	}
+}
+
+func TestLn(t *testing.T) {
+	tests := map[string]Decimal{
+		"0.693147180559945309417232121458":  NewFromInt(2).Ln(30),
+		"-0.693147180559945309417232121458": NewFromFloat(0.5).Ln(30),
+		"2.30258509299404568402":            NewFromInt(10).Ln(20),
+		"0":                                 NewFromInt(1).Ln(10),
+		"230.25850929940457":                New(1, 100).Ln(14),
+		"-230.25850929940457":               New(1, -100).Ln(14),
+	}
+
+	for want, d := range tests {
+		assert.Equal(t, want, d.String())
+	}
+
+	assert.PanicsWithValue(t, "tomath: logarithm of non-positive number", func() { NewFromInt(0).Ln(2) })
+}
+
+func TestLog(t *testing.T) {
+	tests := map[string]Decimal{
+		"3":            NewFromInt(1000).Log10(10),
+		"-2":           NewFromFloat(0.01).Log10(10),
+		"0.30103":      NewFromInt(2).Log10(5),
+		"10":           NewFromInt(1024).Log(NewFromInt(2), 20),
+		"100":          New(1, 100).Log(NewFromInt(10), 5),
+		"-0.5":         NewFromInt(2).Log(NewFromFloat(0.25), 10),
+		"2302586.2443": NewFromInt(10).Log(NewFromFloat(1.000001), 4),
+	}
+
+	for want, d := range tests {
+		assert.Equal(t, want, d.String())
+	}
+
+	assert.PanicsWithValue(t, "tomath: invalid logarithm base 1", func() { NewFromInt(2).Log(NewFromInt(1), 2) })
+	assert.PanicsWithValue(t, "tomath: logarithm of non-positive number", func() { NewFromInt(-2).Log10(2) })
+}
+
+func TestExpLnRoundTrip(t *testing.T) {
+	for _, s := range []string{"0.001", "0.5", "1.5", "42", "123456.789"} {
+		x := RequireFromString(s)
+		assert.Equal(t, s, x.Ln(40).Exp(30).Round(3).String())
+		assert.Equal(t, x.Mul(x).Sqrt(20).String(), x.String())
+	}
+}
+
+func TestSqrtMath(t *testing.T) {
+	precision := int32(decimal.DivisionPrecision)
+	d := NewFromFloatWithName("principal", 1000).
+		Mul(NewFromFloatWithName("rate", 0.05).Mul(NewFromFloatWithName("years", 2)).Exp(precision)).
+		SetName("balance")
+
+	vars, formula := d.Math()
+	assert.Equal(t, "principal * exp(rate * years) = balance", vars)
+	assert.Equal(t, "1000 * exp(0.05 * 2) = 1105.1709180756476", formula)
+
+	vars, formula = NewFromFloatWithName("var1", 2).Sqrt(precision).Add(NewFromFloatWithName("var2", 100).Log(NewFromFloatWithName("var3", 10), precision)).Math()
+	assert.Equal(t, "sqrt(var1) + log(var2, var3) = ?", vars)
+	assert.Equal(t, "sqrt(2) + log(100, 10) = 3.414213562373095", formula)
+
+	steps := NewFromFloatWithName("var1", 2).Ln(precision).Steps()
+	require.Len(t, steps, 1)
+	assert.Equal(t, []int32{precision}, steps[0].Params)
+	assert.Equal(t, "ln(2) = 0.6931471805599453", steps[0].String())
+
+	vars, formula = NewFromFloatWithName("var1", 2).Sqrt(4).Add(NewFromFloatWithName("var2", 100).Log(NewFromFloatWithName("var3", 10), 2)).Math()
+	assert.Equal(t, "sqrt(4)(var1) + log(2)(var2, var3) = ?", vars)
+	assert.Equal(t, "sqrt(4)(2) + log(2)(100, 10) = 3.4142", formula)
+
+	steps = NewFromFloatWithName("var1", 2).Ln(4).Steps()
+	require.Len(t, steps, 1)
+	assert.Equal(t, []int32{4}, steps[0].Params)
+	assert.Equal(t, "ln(4)(2) = 0.6931", steps[0].String())
+}
@@ -0,0 +1,306 @@
+package tomath
+
+import (
+	"strconv"
+	"strings"
+)
+
+type (
+	// Phrasebook provides the wording Explain() uses to describe a computation.
+	Phrasebook interface {
+		// Value phrases a leaf. name is empty for unnamed values.
+		Value(name, value string) string
+		// Operation phrases op applied with params to the already phrased
+		// operands.
+		Operation(op Op, params []int32, operands []string) string
+		// Group encloses the phrase of an operand which is itself an infix
+		// operation so that it is not mistaken for the continuation of the
+		// sentence.
+		Group(phrase string) string
+	}
+
+	// Templates is a Phrasebook built from one template per operation, which
+	// makes it easy to supply other languages.
+	//
+	// The placeholders {0}, {1}, ... are replaced by the phrased operands,
+	// {p0}, {p1}, ... by the parameters and {all} by the list of all the
+	// operands joined with commas and And.
+	//
+	// Example:
+	//
+	//     German := Templates{
+	//         Named:   "{name} ({value})",
+	//         Unnamed: "{value}",
+	//         Grouped: "({phrase})",
+	//         And:     "und",
+	//         Operations: map[Op]string{
+	//             OpAdd:   "{0}, plus {1}",
+	//             OpRound: "{0} gerundet auf {p0} Nachkommastellen",
+	//             OpMax:   "das Maximum von {all}",
+	//         },
+	//     }
+	Templates struct {
+		// Named phrases a named leaf using {name} and {value}.
+		Named string
+		// Unnamed phrases an unnamed leaf using {value}.
+		Unnamed string
+		// Grouped encloses {phrase}.
+		Grouped string
+		// And joins the last two operands of {all}.
+		And string
+		// Operations holds the template of every operation. Operations without
+		// a template are phrased as a call, ex: "sin(var1 (1))".
+		Operations map[Op]string
+	}
+
+	englishPhrasebook struct{}
+)
+
+// English is the default Phrasebook of Explain().
+var English Phrasebook = englishPhrasebook{}
+
+// Explain describes the computation underlying the decimal in plain English.
+//
+// Example:
+//
+//     NewFromFloatWithName("var1", 1.1).
+//         Round(1).
+//         Add(NewFromFloatWithName("var2", 1)).
+//         Div(NewFromFloatWithName("var3", 2)).
+//         Explain()
+//     // output: "var1 (1.1) rounded to 1 decimal place, plus var2 (1), divided by var3 (2)"
+func (d Decimal) Explain() string {
+	return d.ExplainWith(English)
+}
+
+// ExplainWith describes the computation underlying the decimal using the
+// wording of p.
+func (d Decimal) ExplainWith(p Phrasebook) string {
+	return d.node().explain(p)
+}
+
+func (e *Expr) explain(p Phrasebook) string {
+	if e.op == OpLeaf {
+		return p.Value(e.name, e.literal())
+	}
+
+	operands := make([]string, len(e.operands))
+	for i, o := range e.operands {
+		operands[i] = o.explain(p)
+		if isBinary(o.op) && !(i == 0 && isBinary(e.op)) {
+			operands[i] = p.Group(operands[i])
+		}
+	}
+
+	return p.Operation(e.op, e.params, operands)
+}
+
+// isBinary returns whether op is phrased between its two operands.
+func isBinary(op Op) bool {
+	switch op {
+	case OpAdd, OpSub, OpMul, OpDiv, OpMod, OpPow:
+		return true
+	}
+	return false
+}
+
+func (englishPhrasebook) Value(name, value string) string {
+	if name == "" {
+		return value
+	}
+	return name + " (" + value + ")"
+}
+
+func (englishPhrasebook) Group(phrase string) string {
+	return "(" + phrase + ")"
+}
+
+func (p englishPhrasebook) Operation(op Op, params []int32, operands []string) string {
+	if c, ok := contextOf(op, params); ok {
+		return p.Operation(op, nil, operands) + ", " + contextPhrase(c)
+	}
+
+	switch op {
+	case OpAbs:
+		return "the absolute value of " + operands[0]
+	case OpAdd:
+		return operands[0] + ", plus " + operands[1]
+	case OpSub:
+		return operands[0] + ", minus " + operands[1]
+	case OpNeg:
+		return operands[0] + " negated"
+	case OpMul:
+		return operands[0] + ", times " + operands[1]
+	case OpShift:
+		return operands[0] + " shifted by " + places(params[0])
+	case OpDiv:
+		return operands[0] + ", divided by " + operands[1]
+	case OpQuoRem:
+		part := "quotient"
+		if params[1] == 1 {
+			part = "remainder"
+		}
+		return "the " + part + " of " + operands[0] + " divided by " + operands[1] + " to " + places(params[0])
+	case OpDivRound:
+		return operands[0] + " divided by " + operands[1] + " rounded to " + places(params[0])
+	case OpMod:
+		return operands[0] + ", modulo " + operands[1]
+	case OpPow:
+		return operands[0] + ", to the power of " + operands[1]
+	case OpRound:
+		if len(params) == 2 {
+			return operands[0] + " rounded " + roundingPhrase(RoundMode(params[1])) + " to " + places(params[0])
+		}
+		return operands[0] + " rounded to " + places(params[0])
+	case OpRoundBank:
+		return operands[0] + " rounded half to even to " + places(params[0])
+	case OpRoundCash:
+		return operands[0] + " cash rounded to the nearest " + strconv.Itoa(int(params[0])) + " hundredths"
+	case OpFloor:
+		return operands[0] + " rounded down"
+	case OpCeil:
+		return operands[0] + " rounded up"
+	case OpTruncate:
+		return operands[0] + " truncated to " + places(params[0])
+	case OpMin:
+		return "the smallest of " + list(operands, "and")
+	case OpMax:
+		return "the largest of " + list(operands, "and")
+	case OpSum:
+		return "the sum of " + list(operands, "and")
+	case OpAvg:
+		return "the average of " + list(operands, "and")
+	case OpAtan:
+		return "the arctangent of " + operands[0]
+	case OpSin:
+		return "the sine of " + operands[0]
+	case OpCos:
+		return "the cosine of " + operands[0]
+	case OpTan:
+		return "the tangent of " + operands[0]
+	case OpSqrt:
+		return "the square root of " + operands[0] + " to " + places(params[0])
+	case OpExp:
+		return "e to the power of " + operands[0] + " to " + places(params[0])
+	case OpLn:
+		return "the natural logarithm of " + operands[0] + " to " + places(params[0])
+	case OpLog10:
+		return "the decimal logarithm of " + operands[0] + " to " + places(params[0])
+	case OpLog:
+		return "the logarithm of " + operands[0] + " in base " + operands[1] + " to " + places(params[0])
+	case OpAsin:
+		return "the arcsine of " + operands[0]
+	case OpAcos:
+		return "the arccosine of " + operands[0]
+	case OpAtan2:
+		return "the arctangent of " + operands[0] + " over " + operands[1]
+	case OpSinh:
+		return "the hyperbolic sine of " + operands[0]
+	case OpCosh:
+		return "the hyperbolic cosine of " + operands[0]
+	case OpTanh:
+		return "the hyperbolic tangent of " + operands[0]
+	case OpAsinh:
+		return "the inverse hyperbolic sine of " + operands[0]
+	case OpAcosh:
+		return "the inverse hyperbolic cosine of " + operands[0]
+	case OpAtanh:
+		return "the inverse hyperbolic tangent of " + operands[0]
+	case OpRadians:
+		return operands[0] + " converted from degrees to radians"
+	case OpDegrees:
+		return operands[0] + " converted from radians to degrees"
+	case OpRoundToIncrement:
+		return operands[0] + " rounded " + roundingPhrase(RoundMode(params[0])) + " to a multiple of " + operands[1]
+	}
+
+	return call(op, operands)
+}
+
+// contextPhrase phrases how a Context rounds, ex: "to 4 decimal places rounding
+// half to even".
+func contextPhrase(c Context) string {
+	s := "to " + places(c.Precision)
+	if c.Rounding != RoundHalfUp {
+		s += " rounding " + roundingPhrase(c.Rounding)
+	}
+	if c.MaxDigits > 0 {
+		s += " with at most " + strconv.Itoa(int(c.MaxDigits)) + " significant digits"
+	}
+	return s
+}
+
+// roundingPhrase phrases a rounding mode.
+func roundingPhrase(m RoundMode) string {
+	switch m {
+	case RoundHalfUp:
+		return "half away from zero"
+	case RoundHalfEven:
+		return "half to even"
+	case RoundDown:
+		return "toward zero"
+	case RoundCeiling:
+		return "toward positive infinity"
+	case RoundFloor:
+		return "toward negative infinity"
+	case RoundHalfDown:
+		return "half toward zero"
+	case RoundHalfOdd:
+		return "half to odd"
+	case RoundUp:
+		return "away from zero"
+	case Round05Up:
+		return "away from zero after a 0 or a 5"
+	}
+	return m.String()
+}
+
+func places(n int32) string {
+	if n == 1 || n == -1 {
+		return strconv.Itoa(int(n)) + " decimal place"
+	}
+	return strconv.Itoa(int(n)) + " decimal places"
+}
+
+// list joins phrases with commas and the word and before the last one.
+func list(phrases []string, and string) string {
+	if len(phrases) == 1 {
+		return phrases[0]
+	}
+	return strings.Join(phrases[:len(phrases)-1], comma) + " " + and + " " + phrases[len(phrases)-1]
+}
+
+func call(op Op, operands []string) string {
+	return op.String() + leftParen + strings.Join(operands, comma) + rightParen
+}
+
+// Value implements the Phrasebook interface.
+func (t Templates) Value(name, value string) string {
+	if name == "" {
+		return strings.NewReplacer("{value}", value).Replace(t.Unnamed)
+	}
+	return strings.NewReplacer("{name}", name, "{value}", value).Replace(t.Named)
+}
+
+// Group implements the Phrasebook interface.
+func (t Templates) Group(phrase string) string {
+	return strings.NewReplacer("{phrase}", phrase).Replace(t.Grouped)
+}
+
+// Operation implements the Phrasebook interface.
+func (t Templates) Operation(op Op, params []int32, operands []string) string {
+	tmpl, ok := t.Operations[op]
+	if !ok {
+		return call(op, operands)
+	}
+
+	oldnew := []string{"{all}", list(operands, t.And)}
+	for i, o := range operands {
+		oldnew = append(oldnew, "{"+strconv.Itoa(i)+"}", o)
+	}
+	for i, p := range params {
+		oldnew = append(oldnew, "{p"+strconv.Itoa(i)+"}", strconv.Itoa(int(p)))
+	}
+
+	return strings.NewReplacer(oldnew...).Replace(tmpl)
+}
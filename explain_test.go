@@ -0,0 +1,92 @@
+package tomath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplain(t *testing.T) {
+	d := NewFromFloatWithName("var1", 1.1).
+		Round(1).
+		Add(NewFromFloatWithName("var2", 1)).
+		Div(NewFromFloatWithName("var3", 2))
+
+	assert.Equal(t, "var1 (1.1) rounded to 1 decimal place, plus var2 (1), divided by var3 (2)", d.Explain())
+}
+
+func TestExplainGrouping(t *testing.T) {
+	var1 := NewWithName("var1", 1, 0)
+	var2 := NewWithName("var2", 2, 0)
+	var3 := NewWithName("var3", 3, 0)
+
+	assert.Equal(t, "var1 (1), minus (var2 (2), plus var3 (3))", var1.Sub(var2.Add(var3)).Explain())
+	assert.Equal(t, "(var1 (1), plus var2 (2)) rounded to 2 decimal places", var1.Add(var2).Round(2).Explain())
+	assert.Equal(t, "the largest of var1 (1), (var2 (2), times 4) and var3 (3)", Max(var1, var2.Mul(NewFromInt(4)), var3).Explain())
+}
+
+func TestExplainOperations(t *testing.T) {
+	var1 := NewWithName("var1", 5, 0)
+	var2 := NewWithName("var2", 2, 0)
+	q, r := var1.QuoRem(var2, 0)
+
+	tests := map[string]Decimal{
+		"the absolute value of var1 (5)":                                    var1.Abs(),
+		"var1 (5) negated":                                                  var1.Neg(),
+		"var1 (5) shifted by -1 decimal place":                              var1.Shift(-1),
+		"the quotient of var1 (5) divided by var2 (2) to 0 decimal places":  q,
+		"the remainder of var1 (5) divided by var2 (2) to 0 decimal places": r,
+		"var1 (5) divided by var2 (2) rounded to 3 decimal places":          var1.DivRound(var2, 3),
+		"var1 (5), modulo var2 (2)":                                         var1.Mod(var2),
+		"var1 (5), to the power of var2 (2)":                                var1.Pow(var2),
+		"var1 (5) rounded half to even to 2 decimal places":                 var1.RoundBank(2),
+		"var1 (5) cash rounded to the nearest 5 hundredths":                 var1.RoundCash(5),
+		"var1 (5) rounded down":                                             var1.Floor(),
+		"var1 (5) rounded up":                                               var1.Ceil(),
+		"var1 (5) truncated to 1 decimal place":                             var1.Truncate(1),
+		"the smallest of var1 (5) and var2 (2)":                             Min(var1, var2),
+		"the sum of var1 (5)":                                               Sum(var1),
+		"the average of var1 (5), var2 (2) and 3":                           Avg(var1, var2, NewFromInt(3)),
+		"the arctangent of var1 (5)":                                        var1.Atan(),
+		"the sine of var1 (5)":                                              var1.Sin(),
+		"the cosine of var1 (5)":                                            var1.Cos(),
+		"the tangent of var1 (5)":                                           var1.Tan(),
+		"the square root of var1 (5) to 2 decimal places":                   var1.Sqrt(2),
+		"e to the power of var1 (5) to 2 decimal places":                    var1.Exp(2),
+		"the natural logarithm of var1 (5) to 2 decimal places":             var1.Ln(2),
+		"the decimal logarithm of var1 (5) to 2 decimal places":             var1.Log10(2),
+		"the logarithm of var1 (5) in base var2 (2) to 2 decimal places":    var1.Log(var2, 2),
+		"the arctangent of var1 (5) over var2 (2)":                          var1.Atan2(var2),
+		"the hyperbolic sine of var1 (5)":                                   var1.Sinh(),
+		"the inverse hyperbolic cosine of var1 (5)":                         var1.Acosh(),
+		"var1 (5) converted from degrees to radians":                        var1.Radians(),
+		"var1 (5) rounded half to odd to 1 decimal place":                   var1.RoundWith(1, RoundHalfOdd),
+	}
+
+	for want, d := range tests {
+		assert.Equal(t, want, d.Explain())
+	}
+}
+
+func TestExplainWithTemplates(t *testing.T) {
+	german := Templates{
+		Named:   "{name} ({value})",
+		Unnamed: "{value}",
+		Grouped: "({phrase})",
+		And:     "und",
+		Operations: map[Op]string{
+			OpAdd:   "{0}, plus {1}",
+			OpDiv:   "{0}, geteilt durch {1}",
+			OpRound: "{0} gerundet auf {p0} Nachkommastellen",
+			OpMax:   "das Maximum von {all}",
+		},
+	}
+
+	d := NewFromFloatWithName("preis", 1.1).
+		Round(1).
+		Add(NewFromFloatWithName("gebühr", 1)).
+		Div(Max(NewFromFloatWithName("menge", 2), NewFromInt(1)))
+
+	assert.Equal(t, "preis (1.1) gerundet auf 1 Nachkommastellen, plus gebühr (1), geteilt durch das Maximum von menge (2) und 1", d.ExplainWith(german))
+	assert.Equal(t, "sin(preis (1.1))", NewFromFloatWithName("preis", 1.1).Sin().ExplainWith(german))
+}
@@ -0,0 +1,589 @@
+package tomath
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// Op identifies the operation an Expr applies to its operands.
+type Op uint8
+
+const (
+	OpLeaf Op = iota
+	OpAbs
+	OpAdd
+	OpSub
+	OpNeg
+	OpMul
+	OpShift
+	OpDiv
+	OpQuoRem
+	OpDivRound
+	OpMod
+	OpPow
+	OpRound
+	OpRoundBank
+	OpRoundCash
+	OpFloor
+	OpCeil
+	OpTruncate
+	OpMin
+	OpMax
+	OpSum
+	OpAvg
+	OpAtan
+	OpSin
+	OpCos
+	OpTan
+	OpSqrt
+	OpExp
+	OpLn
+	OpLog10
+	OpLog
+	OpAsin
+	OpAcos
+	OpAtan2
+	OpSinh
+	OpCosh
+	OpTanh
+	OpAsinh
+	OpAcosh
+	OpAtanh
+	OpRadians
+	OpDegrees
+	OpRoundToIncrement
+)
+
+var opNames = [...]string{
+	OpLeaf:      "leaf",
+	OpAbs:       abs,
+	OpAdd:       "add",
+	OpSub:       "sub",
+	OpNeg:       neg,
+	OpMul:       "mul",
+	OpShift:     shift,
+	OpDiv:       "div",
+	OpQuoRem:    quoRem,
+	OpDivRound:  divRound,
+	OpMod:       "mod",
+	OpPow:       "pow",
+	OpRound:     round,
+	OpRoundBank: roundBank,
+	OpRoundCash: roundCash,
+	OpFloor:     floor,
+	OpCeil:      ceil,
+	OpTruncate:  truncate,
+	OpMin:       min,
+	OpMax:       max,
+	OpSum:       sum,
+	OpAvg:       avg,
+	OpAtan:      atan,
+	OpSin:       sin,
+	OpCos:       cos,
+	OpTan:       tan,
+	OpSqrt:      sqrt,
+	OpExp:       exp,
+	OpLn:        ln,
+	OpLog10:     log10,
+	OpLog:       log,
+	OpAsin:      asin,
+	OpAcos:      acos,
+	OpAtan2:     atan2,
+	OpSinh:      sinh,
+	OpCosh:      cosh,
+	OpTanh:      tanh,
+	OpAsinh:     asinh,
+	OpAcosh:     acosh,
+	OpAtanh:     atanh,
+	OpRadians:   radians,
+	OpDegrees:   degrees,
+
+	OpRoundToIncrement: roundToIncrement,
+}
+
+// String returns the name of the operation, ex: "add", "round", "quoRem".
+func (o Op) String() string {
+	if int(o) < len(opNames) {
+		return opNames[o]
+	}
+	return "op(" + strconv.Itoa(int(o)) + ")"
+}
+
+// Expr is a node of the expression tree underlying a Decimal. It is immutable.
+//
+// A leaf holds a name and a value. Every other node holds the operation, its
+// parameters (ex: the places of a round), its operands and the value it
+// produced.
+//
+// QuoRem nodes carry the precision followed by 0 for the quotient or 1 for the
+// remainder, which is rendered marked "rem", ex: "quoRem(2, rem)(var1 / var2)".
+// Round nodes produced by RoundWith() carry the places followed by the
+// RoundMode and RoundToIncrement nodes carry the RoundMode.
+//
+// A leaf produced by Resolve() hides the computation it replaced, see
+// Resolved(). A leaf produced by NewMoney() carries the code of its currency
+// and one produced by NewWithUnit() carries its unit. Nodes produced by
+// Percent(), BasisPoints() and PercentChange() carry the notation their value
+// is written in.
+type Expr struct {
+	op       Op
+	name     string
+	value    decimal.Decimal
+	currency string
+	unit     string
+	notation notation
+	params   []int32
+	operands []*Expr
+	resolved *Expr
+	// expanded marks the hidden computation of a resolved leaf substituted for
+	// it by MathOptions.Expand so that it is rendered grouped.
+	expanded bool
+}
+
+// Op returns the operation of the node.
+func (e *Expr) Op() Op {
+	return e.op
+}
+
+// IsLeaf returns whether the node is a leaf.
+func (e *Expr) IsLeaf() bool {
+	return e.op == OpLeaf
+}
+
+// Name returns the name of the node. Intermediate results are only named when
+// SetName() was called on them.
+func (e *Expr) Name() string {
+	return e.name
+}
+
+// Value returns the value produced by the node.
+func (e *Expr) Value() decimal.Decimal {
+	return e.value
+}
+
+// Currency returns the ISO 4217 code of the currency of a leaf produced by
+// NewMoney(), or "".
+func (e *Expr) Currency() string {
+	return e.currency
+}
+
+// Unit returns the unit of a leaf produced by NewWithUnit(), or "".
+func (e *Expr) Unit() string {
+	return e.unit
+}
+
+// Params returns the parameters of the operation, ex: the places of a round.
+func (e *Expr) Params() []int32 {
+	return append([]int32(nil), e.params...)
+}
+
+// Operands returns the operands of the operation in order.
+func (e *Expr) Operands() []*Expr {
+	return append([]*Expr(nil), e.operands...)
+}
+
+// Resolved returns the computation hidden by a leaf produced by Resolve() or
+// ResolveTo(), or nil.
+func (e *Expr) Resolved() *Expr {
+	return e.resolved
+}
+
+// Expr returns the expression tree underlying the decimal.
+func (d Decimal) Expr() *Expr {
+	return d.node()
+}
+
+// Rebind replays the operations which produced d with the leaves named in
+// bindings replaced by the bound values. Leaves missing from bindings keep their
+// value. A replaced leaf keeps its currency and unit. The result has the name
+// of d.
+//
+// Example:
+//
+//     total := NewFromFloatWithName("price", 10).
+//         Mul(NewFromFloatWithName("quantity", 2)).
+//         SetName("total")
+//
+//     vars, formula := total.Rebind(map[string]Decimal{
+//         "quantity": NewFromFloat(3),
+//     }).Math()
+//     // vars:    "price * quantity = total"
+//     // formula: "10 * 3 = 30"
+func (d Decimal) Rebind(bindings map[string]Decimal) Decimal {
+	d2, _ := d.node().eval(func(e *Expr) (Decimal, error) {
+		if b, ok := bindings[e.name]; ok && e.name != "" {
+			l := newLeaf(e.name, b.decimal)
+			l.expr.notation = b.node().notation
+			l.expr.currency, l.expr.unit = e.currency, e.unit
+			return l, nil
+		}
+		return Decimal{name: e.name, decimal: e.value, expr: e}, nil
+	})
+
+	if d.name != "" {
+		d2 = d2.SetName(d.name)
+	}
+	d2.context = d.context
+
+	return d2
+}
+
+// node returns the expression of d. The zero-value Decimal is an unnamed leaf.
+func (d Decimal) node() *Expr {
+	if d.expr == nil {
+		return &Expr{name: d.name, value: d.decimal}
+	}
+	return d.expr
+}
+
+// newLeaf returns a Decimal that is a leaf of the expression tree.
+func newLeaf(name string, d decimal.Decimal) Decimal {
+	return Decimal{name: name, decimal: d, expr: &Expr{name: name, value: d}}
+}
+
+// newOp returns an unnamed Decimal holding value which was produced by op. It
+// has the most precise context of its operands.
+func newOp(value decimal.Decimal, op Op, params []int32, operands ...Decimal) Decimal {
+	e := &Expr{op: op, value: value, params: params, operands: make([]*Expr, len(operands))}
+	for i, o := range operands {
+		e.operands[i] = o.node()
+	}
+
+	return Decimal{decimal: value, expr: e, context: operandsContext(operands...)}
+}
+
+// leafName renders a leaf by its name. An unnamed percentage or basis point
+// is rendered by its value.
+func leafName(e *Expr) string {
+	switch {
+	case e.name != "":
+		return quoteName(e.name)
+	case e.notation != notationPlain:
+		return e.literal()
+	}
+	return unknown
+}
+
+// leafValue renders a leaf by its value.
+func leafValue(e *Expr) string {
+	return e.literal()
+}
+
+// precedence returns how tightly op binds its operands when rendered as text.
+// Operations rendered as calls bind the tightest.
+func precedence(op Op) int {
+	switch op {
+	case OpAdd, OpSub:
+		return 1
+	case OpMul, OpDiv, OpMod:
+		return 2
+	case OpPow:
+		return 3
+	}
+	return 4
+}
+
+// precedence returns how tightly the node binds its operands. An operation
+// recording a Context is rendered as a call.
+func (e *Expr) precedence() int {
+	if _, ok := contextOf(e.op, e.params); ok {
+		return precedence(OpLeaf)
+	}
+	return precedence(e.op)
+}
+
+// needsParens returns whether child has to be wrapped in parentheses when it is
+// an operand of parent in the given mode. right is set for the right operand of
+// a binary operation. Operations of equal precedence associate to the left
+// except Pow which associates to the right, as in Parse().
+func needsParens(parent Op, child *Expr, right bool, mode Parens) bool {
+	switch parent {
+	case OpAdd, OpSub, OpMul, OpDiv, OpMod, OpPow:
+	case OpQuoRem, OpDivRound:
+		parent = OpDiv
+	default:
+		return false
+	}
+
+	p, c := precedence(parent), child.precedence()
+	if mode == ParensFull {
+		return c < precedence(OpLeaf)
+	}
+	if p != c {
+		return c < p
+	}
+	if parent == OpPow {
+		return !right
+	}
+	if mode == ParensMinimal && right {
+		// Additions, subtractions and multiplications are exact so regrouping
+		// them does not change the result.
+		return !(parent == OpAdd || parent == OpMul && child.op == OpMul)
+	}
+	return right
+}
+
+// String renders the expression with the names of its leaves.
+func (e *Expr) String() string {
+	var b strings.Builder
+	e.render(&b, leafName, MathOptions{})
+	return b.String()
+}
+
+// render writes the expression to b using leaf to render its leaves and opts to
+// choose the parentheses and operators.
+func (e *Expr) render(b *strings.Builder, leaf func(*Expr) string, opts MathOptions) {
+	operand := func(child *Expr, right bool) {
+		if child.op == OpLeaf && e.op == OpPow && !right {
+			if s := leaf(child); strings.HasPrefix(s, "-") {
+				// -2^2 would read as the negation of the power.
+				b.WriteString(leftParen + s + rightParen)
+				return
+			}
+		}
+		if needsParens(e.op, child, right, opts.Parens) || child.expanded && child.precedence() < precedence(OpLeaf) {
+			b.WriteString(leftParen)
+			child.render(b, leaf, opts)
+			b.WriteString(rightParen)
+			return
+		}
+		child.render(b, leaf, opts)
+	}
+
+	if c, ok := contextOf(e.op, e.params); ok {
+		b.WriteString(e.op.String() + contextOpen + c.String() + contextClose + leftParen)
+		e.renderArgs(b, leaf, opts)
+		return
+	}
+
+	switch e.op {
+	case OpLeaf:
+		b.WriteString(leaf(e))
+	case OpAdd, OpSub, OpMul, OpDiv, OpMod, OpPow:
+		operand(e.operands[0], false)
+		b.WriteString(opts.infix(e.op))
+		operand(e.operands[1], true)
+	case OpQuoRem, OpDivRound:
+		b.WriteString(e.op.String() + leftParen + e.paramList() + rightParen + leftParen)
+		operand(e.operands[0], false)
+		b.WriteString(opts.infix(OpDiv))
+		operand(e.operands[1], true)
+		b.WriteString(rightParen)
+	case OpShift, OpRound, OpRoundBank, OpRoundCash, OpTruncate:
+		b.WriteString(e.op.String() + leftParen + e.paramList() + rightParen + leftParen)
+		e.operands[0].render(b, leaf, opts)
+		b.WriteString(rightParen)
+	case OpRoundToIncrement, OpSqrt, OpExp, OpLn, OpLog10, OpLog:
+		b.WriteString(e.op.String() + leftParen)
+		if e.showsParams() {
+			b.WriteString(e.paramList() + rightParen + leftParen)
+		}
+		e.renderArgs(b, leaf, opts)
+	default:
+		b.WriteString(e.op.String() + leftParen)
+		e.renderArgs(b, leaf, opts)
+	}
+}
+
+// paramList renders the parameters of a call, ex: "2", "2, halfEven" or
+// "2, rem".
+func (e *Expr) paramList() string {
+	s := strconv.Itoa(int(e.params[0]))
+	if e.isRemainder() {
+		s += comma + rem
+	}
+	if mode, ok := e.roundMode(); ok {
+		if e.op == OpRoundToIncrement {
+			return mode.String()
+		}
+		s += comma + mode.String()
+	}
+	return s
+}
+
+// showsParams returns whether the parameters of a call taking its operands in a
+// second pair of parentheses are rendered. The precision of a square root, an
+// exponential or a logarithm is only rendered when it is not
+// decimal.DivisionPrecision, ex: "sqrt(var1)" but "sqrt(4)(var1)".
+func (e *Expr) showsParams() bool {
+	return e.op == OpRoundToIncrement || e.params[0] != int32(decimal.DivisionPrecision)
+}
+
+// isRemainder returns whether the node is the remainder of a QuoRem.
+func (e *Expr) isRemainder() bool {
+	return e.op == OpQuoRem && len(e.params) > 1 && e.params[1] == 1
+}
+
+// renderArgs writes the operands of a call separated by commas and the closing
+// parenthesis.
+func (e *Expr) renderArgs(b *strings.Builder, leaf func(*Expr) string, opts MathOptions) {
+	for i, o := range e.operands {
+		if i > 0 {
+			b.WriteString(comma)
+		}
+		o.render(b, leaf, opts)
+	}
+	b.WriteString(rightParen)
+}
+
+// infix returns the symbol, surrounded by its spacing, of a binary operation.
+func infix(op Op) string {
+	switch op {
+	case OpAdd:
+		return add
+	case OpSub:
+		return sub
+	case OpMul:
+		return mul
+	case OpDiv:
+		return div
+	case OpMod:
+		return mod
+	case OpPow:
+		return pow
+	}
+	return ""
+}
+
+// eval replays the expression using leaf to produce the Decimal of every leaf.
+// Named intermediate results keep their names.
+func (e *Expr) eval(leaf func(*Expr) (Decimal, error)) (Decimal, error) {
+	if e.op == OpLeaf {
+		return leaf(e)
+	}
+
+	operands := make([]Decimal, len(e.operands))
+	for i, o := range e.operands {
+		d, err := o.eval(leaf)
+		if err != nil {
+			return Decimal{}, err
+		}
+		operands[i] = d
+	}
+
+	return e.finish(apply(e.op, e.params, operands)), nil
+}
+
+// finish gives d, replayed from the node, the notation and the name of the
+// node.
+func (e *Expr) finish(d Decimal) Decimal {
+	if e.notation != notationPlain {
+		d = d.notated(e.notation)
+	}
+	if e.name != "" {
+		d = d.SetName(e.name)
+	}
+	return d
+}
+
+// apply runs op with params on operands.
+func apply(op Op, params []int32, operands []Decimal) Decimal {
+	if c, ok := contextOf(op, params); ok {
+		context := operandsContext(operands...)
+		rounded := make([]Decimal, len(operands))
+		for i, o := range operands {
+			rounded[i] = o.WithContext(c)
+		}
+		d := apply(op, nil, rounded)
+		d.context = context
+		return d
+	}
+
+	switch op {
+	case OpAbs:
+		return operands[0].Abs()
+	case OpAdd:
+		return operands[0].Add(operands[1])
+	case OpSub:
+		return operands[0].Sub(operands[1])
+	case OpNeg:
+		return operands[0].Neg()
+	case OpMul:
+		return operands[0].Mul(operands[1])
+	case OpShift:
+		return operands[0].Shift(params[0])
+	case OpDiv:
+		return operands[0].Div(operands[1])
+	case OpQuoRem:
+		q, r := operands[0].QuoRem(operands[1], params[0])
+		if len(params) > 1 && params[1] == 1 {
+			return r
+		}
+		return q
+	case OpDivRound:
+		return operands[0].DivRound(operands[1], params[0])
+	case OpMod:
+		return operands[0].Mod(operands[1])
+	case OpPow:
+		return operands[0].Pow(operands[1])
+	case OpRound:
+		if len(params) == 2 {
+			return operands[0].RoundWith(params[0], RoundMode(params[1]))
+		}
+		return operands[0].Round(params[0])
+	case OpRoundBank:
+		return operands[0].RoundBank(params[0])
+	case OpRoundCash:
+		return operands[0].RoundCash(uint8(params[0]))
+	case OpFloor:
+		return operands[0].Floor()
+	case OpCeil:
+		return operands[0].Ceil()
+	case OpTruncate:
+		return operands[0].Truncate(params[0])
+	case OpMin:
+		return Min(operands[0], operands[1:]...)
+	case OpMax:
+		return Max(operands[0], operands[1:]...)
+	case OpSum:
+		return Sum(operands[0], operands[1:]...)
+	case OpAvg:
+		return Avg(operands[0], operands[1:]...)
+	case OpAtan:
+		return operands[0].Atan()
+	case OpSin:
+		return operands[0].Sin()
+	case OpCos:
+		return operands[0].Cos()
+	case OpTan:
+		return operands[0].Tan()
+	case OpSqrt:
+		return operands[0].Sqrt(params[0])
+	case OpExp:
+		return operands[0].Exp(params[0])
+	case OpLn:
+		return operands[0].Ln(params[0])
+	case OpLog10:
+		return operands[0].Log10(params[0])
+	case OpLog:
+		return operands[0].Log(operands[1], params[0])
+	case OpAsin:
+		return operands[0].Asin()
+	case OpAcos:
+		return operands[0].Acos()
+	case OpAtan2:
+		return operands[0].Atan2(operands[1])
+	case OpSinh:
+		return operands[0].Sinh()
+	case OpCosh:
+		return operands[0].Cosh()
+	case OpTanh:
+		return operands[0].Tanh()
+	case OpAsinh:
+		return operands[0].Asinh()
+	case OpAcosh:
+		return operands[0].Acosh()
+	case OpAtanh:
+		return operands[0].Atanh()
+	case OpRadians:
+		return operands[0].Radians()
+	case OpDegrees:
+		return operands[0].Degrees()
+	case OpRoundToIncrement:
+		return operands[0].RoundToIncrement(operands[1], RoundMode(params[0]))
+	}
+
+	panic("tomath: unknown operation " + op.String())
+}
@@ -0,0 +1,247 @@
+package tomath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExprLeaf(t *testing.T) {
+	e := NewFromFloatWithName("var1", 1.1).Expr()
+	assert.True(t, e.IsLeaf())
+	assert.Equal(t, OpLeaf, e.Op())
+	assert.Equal(t, "var1", e.Name())
+	assert.Equal(t, "1.1", e.Value().String())
+	assert.Empty(t, e.Params())
+	assert.Empty(t, e.Operands())
+}
+
+func TestExprZeroValue(t *testing.T) {
+	e := Decimal{}.Expr()
+	assert.True(t, e.IsLeaf())
+	assert.Equal(t, "", e.Name())
+	assert.Equal(t, "0", e.Value().String())
+	assert.Equal(t, "?", e.String())
+}
+
+func TestExprTree(t *testing.T) {
+	d := NewFromFloatWithName("var1", 1.15).
+		Round(1).
+		Add(NewFromFloatWithName("var2", 1)).
+		SetName("var3").
+		Mul(NewFromFloatWithName("var4", 2))
+
+	e := d.Expr()
+	assert.Equal(t, OpMul, e.Op())
+	assert.Equal(t, "", e.Name())
+	assert.Equal(t, "4.4", e.Value().String())
+	assert.Equal(t, "(round(1)(var1) + var2) * var4", e.String())
+
+	operands := e.Operands()
+	require.Len(t, operands, 2)
+	assert.Equal(t, OpAdd, operands[0].Op())
+	assert.Equal(t, "var3", operands[0].Name())
+	assert.Equal(t, "2.2", operands[0].Value().String())
+	assert.Equal(t, "var4", operands[1].Name())
+
+	r := operands[0].Operands()[0]
+	assert.Equal(t, OpRound, r.Op())
+	assert.Equal(t, []int32{1}, r.Params())
+	assert.Equal(t, "1.2", r.Value().String())
+}
+
+func TestExprImmutable(t *testing.T) {
+	d := NewWithName("var1", 1, 0).Add(NewWithName("var2", 2, 0))
+	e := d.Expr()
+
+	e.Operands()[0] = nil
+	require.NotNil(t, d.Expr().Operands()[0])
+
+	r := d.Round(2)
+	r.Expr().Params()[0] = 5
+	assert.Equal(t, []int32{2}, r.Expr().Params())
+
+	// naming the result does not change the tree it was built from
+	d.SetName("var3")
+	assert.Equal(t, "", d.Expr().Name())
+}
+
+func TestExprQuoRem(t *testing.T) {
+	q, r := NewFromFloatWithName("var1", 4.333).QuoRem(NewFromFloatWithName("var2", 2.7), 3)
+	assert.Equal(t, OpQuoRem, q.Expr().Op())
+	assert.Equal(t, []int32{3, 0}, q.Expr().Params())
+	assert.Equal(t, "var1var2Quotient", q.Expr().Name())
+	assert.Equal(t, []int32{3, 1}, r.Expr().Params())
+	assert.Equal(t, "var1var2Remainder", r.Expr().Name())
+}
+
+func TestExprVariadic(t *testing.T) {
+	e := Avg(NewWithName("var1", 1, 0), NewWithName("var2", 2, 0), NewWithName("var3", 3, 0)).Expr()
+	assert.Equal(t, OpAvg, e.Op())
+	assert.Len(t, e.Operands(), 3)
+	assert.Equal(t, "avg(var1, var2, var3)", e.String())
+}
+
+func TestExprUnnamedOperand(t *testing.T) {
+	d := NewFromInt(1).Add(NewWithName("var1", 2, 0))
+	vars, formula := d.Math()
+	assert.Equal(t, "? + var1 = ?", vars)
+	assert.Equal(t, "1 + 2 = 3", formula)
+}
+
+func TestSetNameAlias(t *testing.T) {
+	d := NewWithName("var1", 1, 0).SetName("var2")
+	vars, formula := d.Math()
+	assert.Equal(t, "var1 = var2", vars)
+	assert.Equal(t, "1 = 1", formula)
+}
+
+func TestOpString(t *testing.T) {
+	assert.Equal(t, "add", OpAdd.String())
+	assert.Equal(t, "roundBank", OpRoundBank.String())
+	assert.Equal(t, "quoRem", OpQuoRem.String())
+	assert.Equal(t, "op(255)", Op(255).String())
+}
+
+func TestRebind(t *testing.T) {
+	d := NewFromFloatWithName("var1", 1.1).
+		Round(1).
+		Add(NewFromFloatWithName("var2", 1)).
+		Add(NewFromFloatWithName("var2", 1)).
+		Div(NewFromFloatWithName("var3", 2)).
+		Mul(NewFromFloatWithName("var4", 2)).
+		SetName("var5")
+
+	d2 := d.Rebind(map[string]Decimal{
+		"var1": NewFromFloat(2.26),
+		"var2": NewFromFloat(3),
+	})
+
+	vars, formula := d2.Math()
+	assert.Equal(t, "(round(1)(var1) + var2 + var2) / var3 * var4 = var5", vars)
+	assert.Equal(t, "(round(1)(2.26) + 3 + 3) / 2 * 2 = 8.3", formula)
+
+	// d is left untouched
+	_, formula = d.Math()
+	assert.Equal(t, "(round(1)(1.1) + 1 + 1) / 2 * 2 = 3.1", formula)
+}
+
+func TestRebindKeepsNames(t *testing.T) {
+	q, r := NewFromFloatWithName("var1", 4.333).QuoRem(NewFromFloatWithName("var2", 2.7), 3)
+
+	vars, formula := r.Rebind(map[string]Decimal{"var1": NewFromInt(10)}).Math()
+	assert.Equal(t, "quoRem(3, rem)(var1 / var2) = var1var2Remainder", vars)
+	assert.Equal(t, "quoRem(3, rem)(10 / 2.7) = 0.0019", formula)
+
+	d := q.Add(NewWithName("var3", 1, 0)).SetName("var4").Mul(NewWithName("var5", 2, 0))
+	d2 := d.Rebind(map[string]Decimal{"var3": NewFromInt(2)})
+	assert.Equal(t, "var4", d2.Expr().Operands()[0].Name())
+	assert.Equal(t, "7.208", d2.String())
+}
+
+func TestRebindKeepsCurrencyAndUnit(t *testing.T) {
+	price := requireMoney(t, "price", "12.5", "USD")
+	storage, err := NewWithUnit("storage", "500", "MB")
+	require.NoError(t, err)
+
+	d := price.Mul(NewFromIntWithName("quantity", 2)).Amount()
+	d2 := d.Rebind(map[string]Decimal{"price": NewFromInt(20)})
+	assert.Equal(t, "40", d2.String())
+	assert.Equal(t, "USD", d2.Expr().Operands()[0].Currency())
+
+	d2 = storage.Value().Add(NewFromIntWithName("extra", 1)).Rebind(map[string]Decimal{"storage": NewFromInt(600)})
+	assert.Equal(t, "601", d2.String())
+	assert.Equal(t, "MB", d2.Expr().Operands()[0].Unit())
+}
+
+func TestRebindUnnamed(t *testing.T) {
+	d := NewFromInt(2).Mul(NewWithName("var1", 3, 0))
+
+	vars, formula := d.Rebind(map[string]Decimal{"": NewFromInt(5), "var1": NewFromInt(4)}).Math()
+	assert.Equal(t, "? * var1 = ?", vars)
+	assert.Equal(t, "2 * 4 = 8", formula)
+}
+
+func TestMathPrecedence(t *testing.T) {
+	a := NewWithName("a", 7, 0)
+	b := NewWithName("b", 3, 0)
+	c := NewWithName("c", 2, 0)
+
+	tests := map[string]Decimal{
+		"a - (b - c) = ?":              a.Sub(b.Sub(c)),
+		"a - b - c = ?":                a.Sub(b).Sub(c),
+		"a + (b + c) = ?":              a.Add(b.Add(c)),
+		"a / (b / c) = ?":              a.Div(b.Div(c)),
+		"a / b / c = ?":                a.Div(b).Div(c),
+		"a * (b / c) = ?":              a.Mul(b.Div(c)),
+		"a % (b * c) = ?":              a.Mod(b.Mul(c)),
+		"(a^b)^c = ?":                  a.Pow(b).Pow(c),
+		"a^b^c = ?":                    a.Pow(b.Pow(c)),
+		"(a * b)^c = ?":                a.Mul(b).Pow(c),
+		"a^(b * c) = ?":                a.Pow(b.Mul(c)),
+		"a * b^c = ?":                  a.Mul(b.Pow(c)),
+		"a - b * c = ?":                a.Sub(b.Mul(c)),
+		"neg(a + b) = ?":               a.Add(b).Neg(),
+		"divRound(2)(a / (b * c)) = ?": a.DivRound(b.Mul(c), 2),
+		"divRound(2)(a * b / c) = ?":   a.Mul(b).DivRound(c, 2),
+		"round(1)(a - b) * c = ?":      a.Sub(b).Round(1).Mul(c),
+		"max(a - b, c)^(a - c) = ?":    Max(a.Sub(b), c).Pow(a.Sub(c)),
+	}
+
+	for want, d := range tests {
+		vars, _ := d.Math()
+		assert.Equal(t, want, vars)
+	}
+}
+
+// TestMathReparse proves that the text of every combination of two binary
+// operations means what was computed by parsing and evaluating it again.
+func TestMathReparse(t *testing.T) {
+	ops := []Op{OpAdd, OpSub, OpMul, OpDiv, OpMod, OpPow}
+
+	for _, values := range [][3]int64{{7, 3, 2}, {-7, 3, 2}, {7, -3, -2}} {
+		a := NewWithName("a", values[0], 0)
+		b := NewWithName("b", values[1], 0)
+		c := NewWithName("c", values[2], 0)
+
+		for _, op1 := range ops {
+			for _, op2 := range ops {
+				for _, d := range []Decimal{
+					apply(op2, nil, []Decimal{apply(op1, nil, []Decimal{a, b}), c}),
+					apply(op2, nil, []Decimal{a, apply(op1, nil, []Decimal{b, c})}),
+				} {
+					vars, formula := d.Math()
+
+					p, err := Parse(vars, leaves(d))
+					require.NoError(t, err, vars)
+					assert.Equal(t, d.String(), p.String(), vars)
+
+					p, err = Parse(formula, nil)
+					require.NoError(t, err, formula)
+					assert.Equal(t, d.String(), p.String(), formula)
+				}
+			}
+		}
+	}
+
+	_, formula := NewFromIntWithName("n", -2).Pow(NewFromIntWithName("e", 2)).Math()
+	assert.Equal(t, "(-2)^2 = 4", formula)
+	_, formula = NewFromIntWithName("e", 2).Pow(NewFromIntWithName("n", -2)).Neg().Math()
+	assert.Equal(t, "neg(2^-2) = -0.25", formula)
+}
+
+func TestExprResolved(t *testing.T) {
+	sum := NewFromFloatWithName("var1", 1).Add(NewFromFloatWithName("var2", 2)).SetName("var3")
+
+	e := sum.Resolve().Expr()
+	assert.True(t, e.IsLeaf())
+	assert.Equal(t, "var3", e.Name())
+	assert.Equal(t, sum.Expr(), e.Resolved())
+
+	assert.Equal(t, sum.SetName("var4").Expr(), sum.ResolveTo("var4").Resolve().Expr().Resolved())
+	assert.Equal(t, sum.Expr(), sum.Resolve().SetName("var4").Expr().Resolved())
+	assert.Equal(t, sum.Expr(), sum.Resolve().Rebind(nil).Expr().Resolved())
+	assert.Nil(t, sum.Expr().Resolved())
+	assert.Nil(t, NewFromInt(1).Resolve().Expr().Resolved())
+}
@@ -0,0 +1,225 @@
+package tomath
+
+import (
+	"strconv"
+	"strings"
+)
+
+type (
+	// graph is the computation underlying a decimal as a directed graph in
+	// which data flows from the leaves to the result.
+	graph struct {
+		nodes []graphNode
+		edges []graphEdge
+	}
+
+	graphNode struct {
+		id    string
+		leaf  bool
+		lines []string
+	}
+
+	graphEdge struct {
+		from, to string
+		// label is the position of the operand for operations whose operands do
+		// not commute.
+		label string
+	}
+)
+
+// DOT returns the computation underlying the decimal as a Graphviz DOT digraph.
+// Leaves are boxes labeled with their name and value. Operations are ellipses
+// labeled with the operation, their name if any and the value they produced.
+// Named leaves sharing a name and a value are drawn once.
+//
+// Example:
+//
+//     dot := NewFromFloatWithName("var1", 1).
+//         Add(NewFromFloatWithName("var2", 2)).
+//         SetName("var3").
+//         DOT()
+//     // digraph {
+//     // 	n0 [label="add\nvar3 = 3", shape=ellipse];
+//     // 	n1 [label="var1\n1", shape=box];
+//     // 	n2 [label="var2\n2", shape=box];
+//     // 	n1 -> n0;
+//     // 	n2 -> n0;
+//     // }
+func (d Decimal) DOT() string {
+	return newGraph(d.node(), exprName).dot()
+}
+
+// DOTWith returns the computation underlying the decimal as DOT() does, naming
+// the unnamed values according to unnamed, see StepsWith().
+func (d Decimal) DOTWith(unnamed Placeholder) (string, error) {
+	name, err := valueNames(d.node(), d.name, unnamed)
+	if err != nil {
+		return "", err
+	}
+	return newGraph(d.node(), name).dot(), nil
+}
+
+func (g *graph) dot() string {
+	var b strings.Builder
+	b.WriteString("digraph {\n")
+	for _, n := range g.nodes {
+		shape := "ellipse"
+		if n.leaf {
+			shape = "box"
+		}
+
+		lines := make([]string, len(n.lines))
+		for i, l := range n.lines {
+			lines[i] = dotEscaper.Replace(l)
+		}
+		b.WriteString("\t" + n.id + ` [label="` + strings.Join(lines, `\n`) + `", shape=` + shape + "];\n")
+	}
+	for _, e := range g.edges {
+		b.WriteString("\t" + e.from + " -> " + e.to)
+		if e.label != "" {
+			b.WriteString(` [label="` + e.label + `"]`)
+		}
+		b.WriteString(";\n")
+	}
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// Mermaid returns the computation underlying the decimal as a Mermaid flowchart.
+// Leaves are rectangles labeled with their name and value. Operations are
+// rounded rectangles labeled with the operation, their name if any and the
+// value they produced. Named leaves sharing a name and a value are drawn once.
+//
+// Example:
+//
+//     chart := NewFromFloatWithName("var1", 1).
+//         Add(NewFromFloatWithName("var2", 2)).
+//         SetName("var3").
+//         Mermaid()
+//     // graph BT
+//     // 	n0("add<br/>var3 = 3")
+//     // 	n1["var1<br/>1"]
+//     // 	n2["var2<br/>2"]
+//     // 	n1 --> n0
+//     // 	n2 --> n0
+func (d Decimal) Mermaid() string {
+	return newGraph(d.node(), exprName).mermaid()
+}
+
+// MermaidWith returns the computation underlying the decimal as Mermaid()
+// does, naming the unnamed values according to unnamed, see StepsWith().
+func (d Decimal) MermaidWith(unnamed Placeholder) (string, error) {
+	name, err := valueNames(d.node(), d.name, unnamed)
+	if err != nil {
+		return "", err
+	}
+	return newGraph(d.node(), name).mermaid(), nil
+}
+
+func (g *graph) mermaid() string {
+	var b strings.Builder
+	b.WriteString("graph BT\n")
+	for _, n := range g.nodes {
+		lines := make([]string, len(n.lines))
+		for i, l := range n.lines {
+			lines[i] = mermaidEscaper.Replace(l)
+		}
+
+		label := `"` + strings.Join(lines, "<br/>") + `"`
+		if n.leaf {
+			b.WriteString("\t" + n.id + "[" + label + "]\n")
+		} else {
+			b.WriteString("\t" + n.id + "(" + label + ")\n")
+		}
+	}
+	for _, e := range g.edges {
+		b.WriteString("\t" + e.from + " -->")
+		if e.label != "" {
+			b.WriteString("|" + e.label + "|")
+		}
+		b.WriteString(" " + e.to + "\n")
+	}
+
+	return b.String()
+}
+
+var (
+	dotEscaper     = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	mermaidEscaper = strings.NewReplacer(`"`, `#quot;`, `<`, `#lt;`, `>`, `#gt;`)
+)
+
+// newGraph builds the graph of e naming its values with name. Operations are
+// shared when the same result is used as an operand several times.
+func newGraph(e *Expr, name func(*Expr) string) *graph {
+	g := &graph{}
+	ops := map[*Expr]string{}
+	leaves := map[string]string{}
+
+	var visit func(e *Expr) string
+	visit = func(e *Expr) string {
+		if e.op == OpLeaf {
+			n := name(e)
+			key := n + "\x00" + e.literal()
+			if id, ok := leaves[key]; ok && n != "" {
+				return id
+			}
+
+			id := "n" + strconv.Itoa(len(g.nodes))
+			leaves[key] = id
+			g.nodes = append(g.nodes, graphNode{id: id, leaf: true, lines: leafLines(e, n)})
+			return id
+		}
+
+		if id, ok := ops[e]; ok {
+			return id
+		}
+
+		id := "n" + strconv.Itoa(len(g.nodes))
+		ops[e] = id
+		g.nodes = append(g.nodes, graphNode{id: id, lines: opLines(e, name(e))})
+
+		for i, o := range e.operands {
+			edge := graphEdge{from: visit(o), to: id}
+			if !commutes(e.op) {
+				edge.label = strconv.Itoa(i + 1)
+			}
+			g.edges = append(g.edges, edge)
+		}
+
+		return id
+	}
+	visit(e)
+
+	return g
+}
+
+func leafLines(e *Expr, name string) []string {
+	if name == "" {
+		return []string{e.literal()}
+	}
+	return []string{name, e.literal()}
+}
+
+func opLines(e *Expr, name string) []string {
+	label := e.op.String()
+	if c, ok := contextOf(e.op, e.params); ok {
+		label += contextOpen + c.String() + contextClose
+	} else if len(e.params) > 0 {
+		label += leftParen + e.paramList() + rightParen
+	}
+
+	if name == "" {
+		return []string{label, e.literal()}
+	}
+	return []string{label, name + equal + e.literal()}
+}
+
+// commutes returns whether the order of the operands of op does not matter.
+func commutes(op Op) bool {
+	switch op {
+	case OpSub, OpDiv, OpQuoRem, OpDivRound, OpMod, OpPow, OpLog, OpAtan2, OpRoundToIncrement:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,119 @@
+package tomath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDOT(t *testing.T) {
+	d := NewFromFloatWithName("var1", 1.1).
+		Round(1).
+		Add(NewFromFloatWithName("var2", 1)).
+		Add(NewFromFloatWithName("var2", 1)).
+		Div(NewFromFloatWithName("var3", 2)).
+		SetName("var5")
+
+	assert.Equal(t, `digraph {
+	n0 [label="div\nvar5 = 1.55", shape=ellipse];
+	n1 [label="add\n3.1", shape=ellipse];
+	n2 [label="add\n2.1", shape=ellipse];
+	n3 [label="round(1)\n1.1", shape=ellipse];
+	n4 [label="var1\n1.1", shape=box];
+	n5 [label="var2\n1", shape=box];
+	n6 [label="var3\n2", shape=box];
+	n4 -> n3;
+	n3 -> n2;
+	n5 -> n2;
+	n2 -> n1;
+	n5 -> n1;
+	n1 -> n0 [label="1"];
+	n6 -> n0 [label="2"];
+}
+`, d.DOT())
+}
+
+func TestDOTSharedResult(t *testing.T) {
+	d := NewFromFloatWithName("var1", 1).Add(NewFromFloatWithName("var2", 2)).SetName("var3")
+	d = d.Mul(d).Add(d.Resolve())
+
+	assert.Equal(t, `digraph {
+	n0 [label="add\n12", shape=ellipse];
+	n1 [label="mul\n9", shape=ellipse];
+	n2 [label="add\nvar3 = 3", shape=ellipse];
+	n3 [label="var1\n1", shape=box];
+	n4 [label="var2\n2", shape=box];
+	n5 [label="var3\n3", shape=box];
+	n3 -> n2;
+	n4 -> n2;
+	n2 -> n1;
+	n2 -> n1;
+	n1 -> n0;
+	n5 -> n0;
+}
+`, d.DOT())
+}
+
+func TestDOTEscape(t *testing.T) {
+	d := NewWithName(`say "hi" \o/`, 1, 0).Add(NewFromInt(1)).Add(NewFromInt(1))
+
+	assert.Equal(t, `digraph {
+	n0 [label="add\n3", shape=ellipse];
+	n1 [label="add\n2", shape=ellipse];
+	n2 [label="say \"hi\" \\o/\n1", shape=box];
+	n3 [label="1", shape=box];
+	n4 [label="1", shape=box];
+	n2 -> n1;
+	n3 -> n1;
+	n1 -> n0;
+	n4 -> n0;
+}
+`, d.DOT())
+}
+
+func TestMermaid(t *testing.T) {
+	q, _ := NewFromFloatWithName("var1", 4.333).QuoRem(NewFromFloatWithName("var2", 2.7), 3)
+	d := Avg(q, NewWithName(`"var3" <x>`, 2, 0))
+
+	assert.Equal(t, `graph BT
+	n0("avg<br/>1.802")
+	n1("quoRem(3)<br/>var1var2Quotient = 1.604")
+	n2["var1<br/>4.333"]
+	n3["var2<br/>2.7"]
+	n4["#quot;var3#quot; #lt;x#gt;<br/>2"]
+	n2 -->|1| n1
+	n3 -->|2| n1
+	n1 --> n0
+	n4 --> n0
+`, d.Mermaid())
+}
+
+func TestDOTWith(t *testing.T) {
+	d := NewFromFloatWithName("var1", 1).Add(NewFromInt(2)).Mul(NewFromFloatWithName("var2", 3))
+
+	dot, err := d.DOTWith(PlaceholderGenerate)
+	require.NoError(t, err)
+	assert.Equal(t, `digraph {
+	n0 [label="mul\nt2 = 9", shape=ellipse];
+	n1 [label="add\nt3 = 3", shape=ellipse];
+	n2 [label="var1\n1", shape=box];
+	n3 [label="t1\n2", shape=box];
+	n4 [label="var2\n3", shape=box];
+	n2 -> n1;
+	n3 -> n1;
+	n1 -> n0;
+	n4 -> n0;
+}
+`, dot)
+
+	chart, err := d.MermaidWith(PlaceholderGenerate)
+	require.NoError(t, err)
+	assert.Contains(t, chart, "\tn1(\"add<br/>t3 = 3\")\n")
+
+	_, err = d.MermaidWith(PlaceholderError)
+	assert.EqualError(t, err, "tomath: unnamed values 2 and intermediates 3 and result")
+	dot, err = d.DOTWith(PlaceholderUnknown)
+	require.NoError(t, err)
+	assert.Equal(t, d.DOT(), dot)
+}
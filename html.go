@@ -0,0 +1,154 @@
+package tomath
+
+import (
+	"html"
+	"strings"
+)
+
+// MathHTML returns two HTML fragments representing the formula underlying the
+// decimal. The first uses the decimal names. The second uses the decimal values.
+// Both are followed by an equals sign with the current name and value
+// respectively.
+//
+// The fragments are nested spans so they can be styled and inspected:
+//
+//   - "tomath" wraps the whole formula.
+//   - "tomath-op" wraps every operation along with a class per operation, ex:
+//     "tomath-add", "tomath-round".
+//   - "tomath-leaf" wraps every named or unnamed value along with "tomath-name"
+//     or "tomath-value". The data-name and data-value attributes hold both.
+//   - "tomath-operator", "tomath-function", "tomath-param" and "tomath-paren"
+//     wrap the symbols.
+//
+// Example:
+//
+//     vars, _ := NewFromFloatWithName("var1", 1).
+//         Add(NewFromFloatWithName("var2", 2)).
+//         MathHTML()
+//     // vars: `<span class="tomath"><span class="tomath-op tomath-add"><span class="tomath-leaf tomath-name" data-name="var1" data-value="1">var1</span><span class="tomath-operator"> + </span><span class="tomath-leaf tomath-name" data-name="var2" data-value="2">var2</span></span><span class="tomath-operator"> = </span><span class="tomath-leaf tomath-name" data-value="3">?</span></span>`
+func (d Decimal) MathHTML() (string, string) {
+	var vars, formula strings.Builder
+	e := d.node()
+	result := &Expr{name: d.name, value: d.decimal, notation: e.notation}
+
+	vars.WriteString(`<span class="tomath">`)
+	e.html(&vars, htmlLeafName)
+	vars.WriteString(htmlOperator(equal) + htmlLeafName(result) + `</span>`)
+
+	formula.WriteString(`<span class="tomath">`)
+	e.html(&formula, htmlLeafValue)
+	formula.WriteString(htmlOperator(equal) + htmlLeafValue(result) + `</span>`)
+
+	return vars.String(), formula.String()
+}
+
+func htmlLeafName(e *Expr) string {
+	name := e.name
+	if name == "" {
+		name = leafName(e)
+	}
+	return htmlLeaf(e, "tomath-name", name)
+}
+
+func htmlLeafValue(e *Expr) string {
+	return htmlLeaf(e, "tomath-value", e.literal())
+}
+
+func htmlLeaf(e *Expr, class, text string) string {
+	var b strings.Builder
+	b.WriteString(`<span class="tomath-leaf ` + class + `"`)
+	if e.name != "" {
+		b.WriteString(` data-name="` + html.EscapeString(e.name) + `"`)
+	}
+	b.WriteString(` data-value="` + e.value.String() + `">` + html.EscapeString(text) + `</span>`)
+	return b.String()
+}
+
+func htmlOperator(op string) string {
+	return `<span class="tomath-operator">` + op + `</span>`
+}
+
+// html writes the expression to b as nested spans using leaf to render its
+// leaves.
+func (e *Expr) html(b *strings.Builder, leaf func(*Expr) string) {
+	if e.op == OpLeaf {
+		b.WriteString(leaf(e))
+		return
+	}
+
+	paren := func(p string) {
+		b.WriteString(`<span class="tomath-paren">` + p + `</span>`)
+	}
+
+	operand := func(child *Expr, right bool) {
+		if child.op == OpLeaf && e.op == OpPow && !right {
+			if s := leaf(child); strings.Contains(s, `">-`) {
+				paren(leftParen)
+				b.WriteString(s)
+				paren(rightParen)
+				return
+			}
+		}
+		if needsParens(e.op, child, right, ParensStandard) {
+			paren(leftParen)
+			child.html(b, leaf)
+			paren(rightParen)
+			return
+		}
+		child.html(b, leaf)
+	}
+
+	call := func(param string) {
+		b.WriteString(`<span class="tomath-function">` + e.op.String() + `</span>`)
+		if param != "" {
+			b.WriteString(`<span class="tomath-param">` + html.EscapeString(param) + `</span>`)
+		}
+		paren(leftParen)
+	}
+
+	args := func() {
+		for i, o := range e.operands {
+			if i > 0 {
+				b.WriteString(htmlOperator(comma))
+			}
+			o.html(b, leaf)
+		}
+		paren(rightParen)
+	}
+
+	b.WriteString(`<span class="tomath-op tomath-` + e.op.String() + `">`)
+	defer b.WriteString(`</span>`)
+
+	if c, ok := contextOf(e.op, e.params); ok {
+		call(contextOpen + c.String() + contextClose)
+		args()
+		return
+	}
+
+	switch e.op {
+	case OpAdd, OpSub, OpMul, OpDiv, OpMod, OpPow:
+		operand(e.operands[0], false)
+		b.WriteString(htmlOperator(infix(e.op)))
+		operand(e.operands[1], true)
+	case OpQuoRem, OpDivRound:
+		call(leftParen + e.paramList() + rightParen)
+		operand(e.operands[0], false)
+		b.WriteString(htmlOperator(div))
+		operand(e.operands[1], true)
+		paren(rightParen)
+	case OpShift, OpRound, OpRoundBank, OpRoundCash, OpTruncate:
+		call(leftParen + e.paramList() + rightParen)
+		e.operands[0].html(b, leaf)
+		paren(rightParen)
+	case OpRoundToIncrement, OpSqrt, OpExp, OpLn, OpLog10, OpLog:
+		if e.showsParams() {
+			call(leftParen + e.paramList() + rightParen)
+		} else {
+			call("")
+		}
+		args()
+	default:
+		call("")
+		args()
+	}
+}
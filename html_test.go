@@ -0,0 +1,93 @@
+package tomath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMathHTML(t *testing.T) {
+	d := NewFromFloatWithName("var1", 1.15).
+		Round(1).
+		Add(NewFromFloatWithName("var2", 1)).
+		Mul(NewFromFloatWithName("var3", 2)).
+		SetName("var4")
+
+	vars, formula := d.MathHTML()
+	assert.Equal(t, `<span class="tomath">`+
+		`<span class="tomath-op tomath-mul">`+
+		`<span class="tomath-paren">(</span>`+
+		`<span class="tomath-op tomath-add">`+
+		`<span class="tomath-op tomath-round"><span class="tomath-function">round</span><span class="tomath-param">(1)</span><span class="tomath-paren">(</span>`+
+		`<span class="tomath-leaf tomath-name" data-name="var1" data-value="1.15">var1</span>`+
+		`<span class="tomath-paren">)</span></span>`+
+		`<span class="tomath-operator"> + </span>`+
+		`<span class="tomath-leaf tomath-name" data-name="var2" data-value="1">var2</span>`+
+		`</span>`+
+		`<span class="tomath-paren">)</span>`+
+		`<span class="tomath-operator"> * </span>`+
+		`<span class="tomath-leaf tomath-name" data-name="var3" data-value="2">var3</span>`+
+		`</span>`+
+		`<span class="tomath-operator"> = </span>`+
+		`<span class="tomath-leaf tomath-name" data-name="var4" data-value="4.4">var4</span>`+
+		`</span>`, vars)
+	assert.Equal(t, `<span class="tomath">`+
+		`<span class="tomath-op tomath-mul">`+
+		`<span class="tomath-paren">(</span>`+
+		`<span class="tomath-op tomath-add">`+
+		`<span class="tomath-op tomath-round"><span class="tomath-function">round</span><span class="tomath-param">(1)</span><span class="tomath-paren">(</span>`+
+		`<span class="tomath-leaf tomath-value" data-name="var1" data-value="1.15">1.15</span>`+
+		`<span class="tomath-paren">)</span></span>`+
+		`<span class="tomath-operator"> + </span>`+
+		`<span class="tomath-leaf tomath-value" data-name="var2" data-value="1">1</span>`+
+		`</span>`+
+		`<span class="tomath-paren">)</span>`+
+		`<span class="tomath-operator"> * </span>`+
+		`<span class="tomath-leaf tomath-value" data-name="var3" data-value="2">2</span>`+
+		`</span>`+
+		`<span class="tomath-operator"> = </span>`+
+		`<span class="tomath-leaf tomath-value" data-name="var4" data-value="4.4">4.4</span>`+
+		`</span>`, formula)
+}
+
+func TestMathHTMLVariadic(t *testing.T) {
+	vars, _ := Max(NewWithName("var1", 1, 0), NewFromInt(2)).MathHTML()
+	assert.Equal(t, `<span class="tomath">`+
+		`<span class="tomath-op tomath-max"><span class="tomath-function">max</span><span class="tomath-paren">(</span>`+
+		`<span class="tomath-leaf tomath-name" data-name="var1" data-value="1">var1</span>`+
+		`<span class="tomath-operator">, </span>`+
+		`<span class="tomath-leaf tomath-name" data-value="2">?</span>`+
+		`<span class="tomath-paren">)</span></span>`+
+		`<span class="tomath-operator"> = </span>`+
+		`<span class="tomath-leaf tomath-name" data-value="2">?</span>`+
+		`</span>`, vars)
+}
+
+func TestMathHTMLEscape(t *testing.T) {
+	vars, _ := NewWithName(`<script>"x"</script>`, 1, 0).MathHTML()
+	assert.Equal(t, `<span class="tomath">`+
+		`<span class="tomath-leaf tomath-name" data-name="&lt;script&gt;&#34;x&#34;&lt;/script&gt;" data-value="1">&lt;script&gt;&#34;x&#34;&lt;/script&gt;</span>`+
+		`<span class="tomath-operator"> = </span>`+
+		`<span class="tomath-leaf tomath-name" data-name="&lt;script&gt;&#34;x&#34;&lt;/script&gt;" data-value="1">&lt;script&gt;&#34;x&#34;&lt;/script&gt;</span>`+
+		`</span>`, vars)
+}
+
+func TestMathHTMLContext(t *testing.T) {
+	vars, _ := Context{Precision: 2}.Div(NewWithName("var1", 1, 0), NewWithName("var2", 3, 0)).MathHTML()
+	assert.Equal(t, `<span class="tomath">`+
+		`<span class="tomath-op tomath-div"><span class="tomath-function">div</span><span class="tomath-param">[prec=2]</span><span class="tomath-paren">(</span>`+
+		`<span class="tomath-leaf tomath-name" data-name="var1" data-value="1">var1</span>`+
+		`<span class="tomath-operator">, </span>`+
+		`<span class="tomath-leaf tomath-name" data-name="var2" data-value="3">var2</span>`+
+		`<span class="tomath-paren">)</span></span>`+
+		`<span class="tomath-operator"> = </span>`+
+		`<span class="tomath-leaf tomath-name" data-value="0.33">?</span>`+
+		`</span>`, vars)
+}
+
+func TestMathHTMLNegativeBase(t *testing.T) {
+	_, formula := NewWithName("var1", -2, 0).Pow(NewWithName("var2", 2, 0)).MathHTML()
+	assert.Contains(t, formula, `<span class="tomath-op tomath-pow"><span class="tomath-paren">(</span>`+
+		`<span class="tomath-leaf tomath-value" data-name="var1" data-value="-2">-2</span>`+
+		`<span class="tomath-paren">)</span><span class="tomath-operator">^</span>`)
+}
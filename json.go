@@ -0,0 +1,309 @@
+package tomath
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"github.com/shopspring/decimal"
+)
+
+type (
+	// Traced wraps a Decimal so that it is serialized to JSON along with its
+	// name and the full expression tree underlying it instead of only its value.
+	//
+	// Example:
+	//
+	//     b, err := json.Marshal(Traced{NewFromFloatWithName("var1", 1).
+	//         Add(NewFromFloatWithName("var2", 2)).
+	//         SetName("var3")})
+	//     // {"name":"var3","value":"3","expr":{"op":"add","name":"var3","value":"3","operands":[
+	//     //     {"name":"var1","value":"1"},{"name":"var2","value":"2"}]}}
+	//
+	// Decoding restores the tree verbatim, values included, so the Math() output
+	// of the decoded Decimal is identical to the encoded one.
+	Traced struct {
+		Decimal
+	}
+
+	// Envelope wraps a Decimal so that its JSON, text, binary and gob encodings
+	// carry its name, its formula and the expression tree underlying it, as
+	// MarshalEnvelope does for every Decimal, without changing the encoding of
+	// the other decimals of the process.
+	//
+	// Example:
+	//
+	//     b, err := json.Marshal(Envelope{NewFromFloatWithName("var1", 1).
+	//         Add(NewFromFloatWithName("var2", 2)).
+	//         SetName("var3")})
+	//     // {"name":"var3","value":"3","expr":{...},"vars":"var1 + var2 = var3","formula":"1 + 2 = 3"}
+	//
+	// It decodes both encodings like a Decimal does.
+	Envelope struct {
+		Decimal
+	}
+
+	tracedJSON struct {
+		Name  string          `json:"name,omitempty"`
+		Value decimal.Decimal `json:"value"`
+		Expr  *exprJSON       `json:"expr"`
+	}
+
+	// envelopeJSON is the encoding of a Decimal when MarshalEnvelope is set.
+	// The vars and formula are informative only, decoding rebuilds them from
+	// the expression tree.
+	envelopeJSON struct {
+		tracedJSON
+		Vars    string `json:"vars"`
+		Formula string `json:"formula"`
+	}
+
+	exprJSON struct {
+		Op       string          `json:"op,omitempty"`
+		Name     string          `json:"name,omitempty"`
+		Value    decimal.Decimal `json:"value"`
+		Currency string          `json:"currency,omitempty"`
+		Unit     string          `json:"unit,omitempty"`
+		Notation string          `json:"notation,omitempty"`
+		Params   []int32         `json:"params,omitempty"`
+		Operands []*exprJSON     `json:"operands,omitempty"`
+		Resolved *exprJSON       `json:"resolved,omitempty"`
+	}
+)
+
+// MarshalJSON implements the json.Marshaler interface.
+func (t Traced) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.Decimal.traced())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (t *Traced) UnmarshalJSON(data []byte) error {
+	var v tracedJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	d, err := v.decimal()
+	if err != nil {
+		return err
+	}
+
+	t.Decimal = d
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (e Envelope) MarshalJSON() ([]byte, error) {
+	return e.Decimal.marshalEnvelope()
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (e Envelope) MarshalText() ([]byte, error) {
+	return e.Decimal.marshalEnvelope()
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (e Envelope) MarshalBinary() ([]byte, error) {
+	return e.Decimal.marshalBinaryEnvelope()
+}
+
+// GobEncode implements the gob.GobEncoder interface.
+func (e Envelope) GobEncode() ([]byte, error) {
+	return e.Decimal.marshalBinaryEnvelope()
+}
+
+// binaryEnvelope prefixes the binary encoding of a Decimal when
+// MarshalEnvelope is set. Read as the exponent of the value only binary
+// encoding it would be 1953459553 which is out of any practical range.
+var binaryEnvelope = []byte("tomath\x00")
+
+// isEnvelope returns whether a JSON or text encoding is an envelope rather than
+// a value.
+func isEnvelope(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	return len(data) > 0 && data[0] == '{'
+}
+
+func (d Decimal) marshalEnvelope() ([]byte, error) {
+	vars, formula := d.Math()
+	return json.Marshal(envelopeJSON{tracedJSON: d.traced(), Vars: vars, Formula: formula})
+}
+
+func (d Decimal) marshalBinaryEnvelope() ([]byte, error) {
+	b, err := d.marshalEnvelope()
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte(nil), binaryEnvelope...), b...), nil
+}
+
+func (d *Decimal) unmarshalEnvelope(data []byte) error {
+	var v envelopeJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	d2, err := v.decimal()
+	if err != nil {
+		return err
+	}
+
+	*d = d2
+	return nil
+}
+
+func (d Decimal) traced() tracedJSON {
+	return tracedJSON{Name: d.name, Value: d.decimal, Expr: newExprJSON(d.node())}
+}
+
+func (v tracedJSON) decimal() (Decimal, error) {
+	if v.Expr == nil {
+		return Decimal{}, errors.New("tomath: traced decimal without expression")
+	}
+
+	e, err := v.Expr.expr()
+	if err != nil {
+		return Decimal{}, err
+	}
+
+	return Decimal{name: v.Name, decimal: v.Value, expr: e}, nil
+}
+
+func newExprJSON(e *Expr) *exprJSON {
+	v := &exprJSON{Name: e.name, Value: e.value, Currency: e.currency, Unit: e.unit, Notation: notations[e.notation].name, Params: e.params}
+	if e.op != OpLeaf {
+		v.Op = e.op.String()
+	}
+
+	for _, o := range e.operands {
+		v.Operands = append(v.Operands, newExprJSON(o))
+	}
+	if e.resolved != nil {
+		v.Resolved = newExprJSON(e.resolved)
+	}
+
+	return v
+}
+
+func (v *exprJSON) expr() (*Expr, error) {
+	if v == nil {
+		return nil, errors.New("tomath: null operand")
+	}
+
+	e := &Expr{name: v.Name, value: v.Value, currency: v.Currency, unit: v.Unit, params: v.Params}
+	n, ok := lookupNotation(v.Notation)
+	if !ok {
+		return nil, errors.New("tomath: unknown notation " + v.Notation)
+	}
+	e.notation = n
+	if v.Op != "" {
+		op, ok := lookupOp(v.Op)
+		if !ok {
+			return nil, errors.New("tomath: unknown operation " + v.Op)
+		}
+		e.op = op
+	}
+
+	for _, o := range v.Operands {
+		operand, err := o.expr()
+		if err != nil {
+			return nil, err
+		}
+		e.operands = append(e.operands, operand)
+	}
+
+	if v.Resolved != nil {
+		resolved, err := v.Resolved.expr()
+		if err != nil {
+			return nil, err
+		}
+		e.resolved = resolved
+	}
+
+	if err := e.check(); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// lookupOp returns the operation named name.
+func lookupOp(name string) (Op, bool) {
+	for op, n := range opNames {
+		if n == name {
+			return Op(op), true
+		}
+	}
+	return 0, false
+}
+
+// check returns an error when the number of operands or parameters of the node
+// does not match its operation or when a parameter is out of range.
+func (e *Expr) check() error {
+	if e.resolved != nil && e.op != OpLeaf {
+		return errors.New("tomath: " + e.op.String() + " cannot hide a computation")
+	}
+
+	if _, ok := LookupCurrency(e.currency); e.currency != "" && (!ok || e.op != OpLeaf) {
+		return errors.New("tomath: unexpected currency " + e.currency)
+	}
+	if u, err := ParseUnit(e.unit); err != nil || e.unit != u.String() || e.unit != "" && e.op != OpLeaf {
+		return errors.New("tomath: unexpected unit " + strconv.Quote(e.unit))
+	}
+
+	operands, params := 1, 0
+	switch e.op {
+	case OpLeaf:
+		operands = 0
+	case OpAdd, OpSub, OpMul, OpDiv, OpMod, OpPow, OpAtan2:
+		operands = 2
+	case OpQuoRem:
+		operands, params = 2, 2
+	case OpDivRound:
+		operands, params = 2, 1
+	case OpLog, OpRoundToIncrement:
+		operands, params = 2, 1
+	case OpShift, OpRound, OpRoundBank, OpRoundCash, OpTruncate, OpSqrt, OpExp, OpLn, OpLog10:
+		params = 1
+	case OpMin, OpMax, OpSum, OpAvg:
+		if len(e.operands) == 0 || len(e.params) != 0 {
+			return errors.New("tomath: " + e.op.String() + " expects at least one operand")
+		}
+		return nil
+	}
+
+	if c, ok := contextOf(e.op, e.params); ok {
+		if !c.Rounding.valid() {
+			return errors.New("tomath: unknown rounding mode " + c.Rounding.String())
+		}
+		params = contextParams
+	}
+	if e.op == OpRound && len(e.params) == 2 {
+		params = 2
+	}
+	if mode, ok := e.roundMode(); ok && !mode.valid() {
+		return errors.New("tomath: unknown rounding mode " + mode.String())
+	}
+
+	if len(e.operands) != operands || len(e.params) != params {
+		return errors.New("tomath: " + e.op.String() + " expects " + plural(operands, "operand") + " and " + plural(params, "parameter"))
+	}
+
+	switch {
+	case e.op == OpQuoRem && e.params[1] != 0 && e.params[1] != 1:
+		return errors.New("tomath: unknown quoRem part " + strconv.Itoa(int(e.params[1])))
+	case e.op == OpRoundCash && !validCashInterval(e.params[0]):
+		return errors.New("tomath: invalid roundCash interval " + strconv.Itoa(int(e.params[0])))
+	}
+
+	return nil
+}
+
+func plural(n int, word string) string {
+	s := strconv.Itoa(n) + " " + word
+	if n != 1 {
+		s += "s"
+	}
+	return s
+}
@@ -0,0 +1,315 @@
+package tomath
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracedJSON(t *testing.T) {
+	d := NewFromFloatWithName("var1", 1.1).
+		Round(1).
+		Add(NewFromFloatWithName("var2", 1)).
+		SetName("var3")
+
+	b, err := json.Marshal(Traced{d})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"name": "var3",
+		"value": "2.1",
+		"expr": {
+			"op": "add",
+			"name": "var3",
+			"value": "2.1",
+			"operands": [
+				{"op": "round", "value": "1.1", "params": [1], "operands": [{"name": "var1", "value": "1.1"}]},
+				{"name": "var2", "value": "1"}
+			]
+		}
+	}`, string(b))
+
+	var t2 Traced
+	require.NoError(t, json.Unmarshal(b, &t2))
+	assert.Equal(t, "var3", t2.GetName())
+	assert.Equal(t, "2.1", t2.String())
+
+	vars, formula := t2.Math()
+	assert.Equal(t, "round(1)(var1) + var2 = var3", vars)
+	assert.Equal(t, "round(1)(1.1) + 1 = 2.1", formula)
+}
+
+func TestTracedJSONRoundTrip(t *testing.T) {
+	var1 := NewFromFloatWithName("var1", 4.333)
+	var2 := NewFromFloatWithName("var2", 2.7)
+	_, r := var1.QuoRem(var2, 3)
+
+	tests := map[string]Decimal{
+		"zero":      {},
+		"unnamed":   NewFromInt(1),
+		"alias":     var1.SetName("var3"),
+		"remainder": r,
+		"complex": Avg(var1.Shift(2), var2.DivRound(var1, 2)).
+			Sub(var1.Mul(var2.Neg()).Pow(NewFromInt(2))).
+			RoundCash(5).
+			SetName("var4"),
+		"roundWith": var1.RoundWith(1, RoundCeiling).RoundToIncrement(var2, RoundHalfDown),
+		"context":   var1.WithContext(Context{Precision: 2, Rounding: RoundHalfEven}).Div(var2).Atan(),
+	}
+
+	for name, d := range tests {
+		t.Run(name, func(t *testing.T) {
+			b, err := json.Marshal(Traced{d})
+			require.NoError(t, err)
+
+			var t2 Traced
+			require.NoError(t, json.Unmarshal(b, &t2))
+
+			vars, formula := d.Math()
+			vars2, formula2 := t2.Math()
+			assert.Equal(t, vars, vars2)
+			assert.Equal(t, formula, formula2)
+			assert.Equal(t, d.GetName(), t2.GetName())
+		})
+	}
+}
+
+func TestTracedJSONCurrencyAndUnit(t *testing.T) {
+	price, err := NewMoney("price", "12.5", "USD")
+	require.NoError(t, err)
+
+	b, err := json.Marshal(Traced{price.Mul(NewFromIntWithName("quantity", 3)).Amount()})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"value": "37.5", "expr": {"op": "mul", "value": "37.5", "operands": [
+		{"name": "price", "value": "12.5", "currency": "USD"},
+		{"name": "quantity", "value": "3"}
+	]}}`, string(b))
+
+	var t2 Traced
+	require.NoError(t, json.Unmarshal(b, &t2))
+	assert.Equal(t, "USD", t2.Expr().Operands()[0].Currency())
+	assert.Equal(t, "", t2.Expr().Operands()[1].Currency())
+
+	storage, err := NewWithUnit("storage", "1.5", "GB")
+	require.NoError(t, err)
+	b, err = json.Marshal(Traced{storage.Value()})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name": "storage", "value": "1.5", "expr": {"name": "storage", "value": "1.5", "unit": "GB"}}`, string(b))
+	require.NoError(t, json.Unmarshal(b, &t2))
+	assert.Equal(t, "GB", t2.Expr().Unit())
+
+	b, err = json.Marshal(Traced{Percent(NewFromFloatWithName("rate", 8.25))})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name": "rate", "value": "0.0825", "expr": {"name": "rate", "value": "0.0825", "notation": "percent"}}`, string(b))
+	require.NoError(t, json.Unmarshal(b, &t2))
+	_, formula := t2.Math()
+	assert.Equal(t, "8.25% = 8.25%", formula)
+}
+
+func TestTracedJSONInStruct(t *testing.T) {
+	type invoice struct {
+		Total Traced `json:"total"`
+	}
+
+	b, err := json.Marshal(invoice{Total: Traced{NewWithName("var1", 1, 0).Neg()}})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"total": {"value": "-1", "expr": {"op": "neg", "value": "-1", "operands": [{"name": "var1", "value": "1"}]}}}`, string(b))
+
+	var i invoice
+	require.NoError(t, json.Unmarshal(b, &i))
+	vars, _ := i.Total.Math()
+	assert.Equal(t, "neg(var1) = ?", vars)
+}
+
+func TestTracedJSONErrors(t *testing.T) {
+	tests := map[string]string{
+		`{"value": "1"}`: "tomath: traced decimal without expression",
+		`{"value": "1", "expr": {"op": "foo", "value": "1"}}`:                                                                    "tomath: unknown operation foo",
+		`{"value": "1", "expr": {"op": "add", "value": "1", "operands": [{"value": "1"}]}}`:                                      "tomath: add expects 2 operands and 0 parameters",
+		`{"value": "1", "expr": {"op": "round", "value": "1", "operands": [{"value": "1"}]}}`:                                    "tomath: round expects 1 operand and 1 parameter",
+		`{"value": "1", "expr": {"op": "sum", "value": "1"}}`:                                                                    "tomath: sum expects at least one operand",
+		`{"value": "1", "expr": {"op": "neg", "value": "1", "operands": [null]}}`:                                                "tomath: null operand",
+		`{"value": "1", "expr": {"value": "1", "operands": [{"value": "1"}]}}`:                                                   "tomath: leaf expects 0 operands and 0 parameters",
+		`{"value": "1", "expr": {"op": "neg", "value": "1", "operands": [{"value": "1"}], "resolved": {"value": "1"}}}`:          "tomath: neg cannot hide a computation",
+		`{"value": "1", "expr": {"op": "sin", "value": "1", "params": [2, 12, 0], "operands": [{"value": "1"}]}}`:                "tomath: unknown rounding mode roundMode(12)",
+		`{"value": "1", "expr": {"op": "round", "value": "1", "params": [2, 12], "operands": [{"value": "1"}]}}`:                 "tomath: unknown rounding mode roundMode(12)",
+		`{"value": "1", "expr": {"value": "1", "currency": "ABC"}}`:                                                              "tomath: unexpected currency ABC",
+		`{"value": "1", "expr": {"op": "neg", "value": "1", "currency": "USD", "operands": [{"value": "1"}]}}`:                   "tomath: unexpected currency USD",
+		`{"value": "1", "expr": {"value": "1", "unit": "GB/"}}`:                                                                  `tomath: unexpected unit "GB/"`,
+		`{"value": "1", "expr": {"op": "neg", "value": "1", "unit": "GB", "operands": [{"value": "1"}]}}`:                        `tomath: unexpected unit "GB"`,
+		`{"value": "1", "expr": {"op": "quoRem", "value": "1", "params": [2, 5], "operands": [{"value": "1"}, {"value": "1"}]}}`: "tomath: unknown quoRem part 5",
+		`{"value": "1", "expr": {"op": "roundCash", "value": "1", "params": [7], "operands": [{"value": "1"}]}}`:                 "tomath: invalid roundCash interval 7",
+		`{"value": "1", "expr": {"value": "1", "notation": "permille"}}`:                                                         "tomath: unknown notation permille",
+	}
+
+	for data, want := range tests {
+		var d Traced
+		assert.EqualError(t, json.Unmarshal([]byte(data), &d), want, data)
+	}
+}
+
+func TestEnvelope(t *testing.T) {
+	MarshalEnvelope = true
+	defer func() { MarshalEnvelope = false }()
+
+	d := NewFromFloatWithName("var1", 1.1).
+		Round(1).
+		Add(NewFromFloatWithName("var2", 1)).
+		SetName("var3")
+	vars, formula := d.Math()
+
+	b, err := d.MarshalJSON()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"name": "var3",
+		"value": "2.1",
+		"vars": "round(1)(var1) + var2 = var3",
+		"formula": "round(1)(1.1) + 1 = 2.1",
+		"expr": {
+			"op": "add",
+			"name": "var3",
+			"value": "2.1",
+			"operands": [
+				{"op": "round", "value": "1.1", "params": [1], "operands": [{"name": "var1", "value": "1.1"}]},
+				{"name": "var2", "value": "1"}
+			]
+		}
+	}`, string(b))
+
+	decoders := map[string]struct {
+		marshal   func(Decimal) ([]byte, error)
+		unmarshal func(*Decimal, []byte) error
+	}{
+		"json":   {Decimal.MarshalJSON, (*Decimal).UnmarshalJSON},
+		"text":   {Decimal.MarshalText, (*Decimal).UnmarshalText},
+		"binary": {Decimal.MarshalBinary, (*Decimal).UnmarshalBinary},
+		"gob":    {Decimal.GobEncode, (*Decimal).GobDecode},
+	}
+
+	for name, test := range decoders {
+		t.Run(name, func(t *testing.T) {
+			b, err := test.marshal(d)
+			require.NoError(t, err)
+
+			d2 := &Decimal{}
+			require.NoError(t, test.unmarshal(d2, b))
+			assert.Equal(t, "var3", d2.GetName())
+
+			vars2, formula2 := d2.Math()
+			assert.Equal(t, vars, vars2)
+			assert.Equal(t, formula, formula2)
+		})
+	}
+}
+
+func TestEnvelopeGob(t *testing.T) {
+	MarshalEnvelope = true
+	defer func() { MarshalEnvelope = false }()
+
+	type invoice struct {
+		Total Decimal
+	}
+
+	var buf bytes.Buffer
+	d := NewWithName("var1", 2, 0).Mul(NewWithName("var2", 3, 0)).SetName("var3")
+	require.NoError(t, gob.NewEncoder(&buf).Encode(invoice{Total: d}))
+
+	var i invoice
+	require.NoError(t, gob.NewDecoder(&buf).Decode(&i))
+	vars, formula := i.Total.Math()
+	assert.Equal(t, "var1 * var2 = var3", vars)
+	assert.Equal(t, "2 * 3 = 6", formula)
+}
+
+func TestEnvelopeWrapper(t *testing.T) {
+	d := NewFromFloatWithName("var1", 1.1).
+		Round(1).
+		Add(NewFromFloatWithName("var2", 1)).
+		SetName("var3")
+	vars, formula := d.Math()
+
+	encoders := map[string]struct {
+		marshal   func(Envelope) ([]byte, error)
+		unmarshal func(*Envelope, []byte) error
+	}{
+		"json":   {Envelope.MarshalJSON, (*Envelope).UnmarshalJSON},
+		"text":   {Envelope.MarshalText, (*Envelope).UnmarshalText},
+		"binary": {Envelope.MarshalBinary, (*Envelope).UnmarshalBinary},
+		"gob":    {Envelope.GobEncode, (*Envelope).GobDecode},
+	}
+
+	for name, test := range encoders {
+		t.Run(name, func(t *testing.T) {
+			b, err := test.marshal(Envelope{d})
+			require.NoError(t, err)
+
+			e := &Envelope{}
+			require.NoError(t, test.unmarshal(e, b))
+			assert.Equal(t, "var3", e.GetName())
+
+			vars2, formula2 := e.Math()
+			assert.Equal(t, vars, vars2)
+			assert.Equal(t, formula, formula2)
+		})
+	}
+
+	// the other decimals keep their value only encoding
+	b, err := json.Marshal(struct {
+		Wrapped Envelope
+		Plain   Decimal
+	}{Envelope{d}, d})
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"vars":"round(1)(var1) + var2 = var3"`)
+	assert.Contains(t, string(b), `"Plain":"2.1"`)
+
+	type invoice struct {
+		Total Envelope
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&buf).Encode(invoice{Total: Envelope{d}}))
+
+	var i invoice
+	require.NoError(t, gob.NewDecoder(&buf).Decode(&i))
+	vars2, formula2 := i.Total.Math()
+	assert.Equal(t, vars, vars2)
+	assert.Equal(t, formula, formula2)
+}
+
+func TestEnvelopeDecodesValues(t *testing.T) {
+	d := NewWithName("var1", 2, 0).Mul(NewWithName("var2", 3, 0)).SetName("var3")
+
+	b, err := d.MarshalJSON()
+	require.NoError(t, err)
+	assert.Equal(t, `"6"`, string(b))
+
+	b, err = d.GobEncode()
+	require.NoError(t, err)
+
+	d2 := &Decimal{}
+	require.NoError(t, d2.GobDecode(b))
+	vars, formula := d2.Math()
+	assert.Equal(t, "? = ?", vars)
+	assert.Equal(t, "6 = 6", formula)
+}
+
+func TestTracedJSONResolved(t *testing.T) {
+	d := NewFromFloatWithName("var1", 1).
+		Add(NewFromFloatWithName("var2", 2)).
+		ResolveTo("var3").
+		Mul(NewFromFloatWithName("var4", 2))
+
+	b, err := json.Marshal(Traced{d})
+	require.NoError(t, err)
+
+	var t2 Traced
+	require.NoError(t, json.Unmarshal(b, &t2))
+
+	vars, formula, err := t2.MathWith(MathOptions{ExpandDepth: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "(var1 + var2) * var4 = ?", vars)
+	assert.Equal(t, "(1 + 2) * 2 = 6", formula)
+}
@@ -0,0 +1,254 @@
+package tomath
+
+import (
+	"strconv"
+	"strings"
+)
+
+var latexEscaper = strings.NewReplacer(
+	`\`, `\backslash{}`,
+	`_`, `\_`,
+	`%`, `\%`,
+	`&`, `\&`,
+	`#`, `\#`,
+	`$`, `\$`,
+	`{`, `\{`,
+	`}`, `\}`,
+	`~`, `\sim{}`,
+	`^`, `\wedge{}`,
+)
+
+var latexFuncs = map[Op]string{
+	OpRound:     `\operatorname{round}`,
+	OpRoundBank: `\operatorname{roundBank}`,
+	OpRoundCash: `\operatorname{roundCash}`,
+	OpTruncate:  `\operatorname{truncate}`,
+	OpQuoRem:    `\operatorname{quoRem}`,
+	OpDivRound:  `\operatorname{divRound}`,
+	OpMin:       `\min`,
+	OpMax:       `\max`,
+	OpSum:       `\sum`,
+	OpAvg:       `\operatorname{avg}`,
+	OpAtan:      `\arctan`,
+	OpSin:       `\sin`,
+	OpCos:       `\cos`,
+	OpTan:       `\tan`,
+	OpExp:       `\exp`,
+	OpLn:        `\ln`,
+	OpLog10:     `\log_{10}`,
+	OpAsin:      `\arcsin`,
+	OpAcos:      `\arccos`,
+	OpAtan2:     `\operatorname{atan2}`,
+	OpSinh:      `\sinh`,
+	OpCosh:      `\cosh`,
+	OpTanh:      `\tanh`,
+	OpAsinh:     `\operatorname{arsinh}`,
+	OpAcosh:     `\operatorname{arcosh}`,
+	OpAtanh:     `\operatorname{artanh}`,
+	OpRadians:   `\operatorname{radians}`,
+	OpDegrees:   `\operatorname{degrees}`,
+
+	OpRoundToIncrement: `\operatorname{roundToIncrement}`,
+}
+
+// MathLaTeX returns two LaTeX math mode strings representing the formula
+// underlying the decimal. The first uses the decimal names. The second uses the
+// decimal values. Both are followed by an equals sign with the current name and
+// value respectively.
+//
+// Example:
+//
+//     vars, formula := NewFromFloatWithName("net_price", 3).
+//         Div(NewFromFloatWithName("var2", 2)).
+//         SetName("var3").
+//         MathLaTeX()
+//     // vars:    "\frac{\mathrm{net\_price}}{\mathrm{var2}} = \mathrm{var3}"
+//     // formula: "\frac{3}{2} = 1.5"
+func (d Decimal) MathLaTeX() (string, string) {
+	var vars, formula strings.Builder
+	e := d.node()
+	e.latex(&vars, latexLeafName)
+	e.latex(&formula, latexLeafValue)
+
+	return vars.String() + equal + latexName(d.name),
+		formula.String() + equal + latexLeafValue(e)
+}
+
+func latexName(name string) string {
+	if name == "" {
+		return unknown
+	}
+	return `\mathrm{` + latexEscaper.Replace(name) + `}`
+}
+
+func latexLeafName(e *Expr) string {
+	if e.name == "" && e.notation != notationPlain {
+		return latexLeafValue(e)
+	}
+	return latexName(e.name)
+}
+
+// latexLeafValue renders a leaf by its value in its notation, ex: `8.25\%`.
+func latexLeafValue(e *Expr) string {
+	v, _ := e.number()
+	switch e.notation {
+	case notationPercent:
+		return v.String() + `\%`
+	case notationBasisPoints:
+		return v.String() + `\,\mathrm{bp}`
+	}
+	return v.String()
+}
+
+// fracParens returns whether child has to be wrapped in parentheses when it is
+// an operand of parent for renderers drawing divisions as fractions. right is
+// set for the right operand of a binary operation.
+func fracParens(parent Op, child *Expr, right bool) bool {
+	switch parent {
+	case OpAdd:
+		return right && child.op == OpNeg
+	case OpSub:
+		return right && (child.op == OpAdd || child.op == OpSub || child.op == OpNeg)
+	case OpMul, OpMod, OpNeg, OpShift:
+		return child.op == OpAdd || child.op == OpSub || child.op == OpMod || child.op == OpNeg ||
+			(right && (child.op == OpMul || child.op == OpShift))
+	case OpPow:
+		return !right && isInfix(child.op)
+	}
+
+	return false
+}
+
+// negativeParens returns whether a negative number has to be wrapped in
+// parentheses when it is an operand of parent.
+func negativeParens(parent Op, right bool) bool {
+	switch parent {
+	case OpAdd, OpSub:
+		return right
+	case OpMul, OpMod, OpNeg, OpShift, OpPow:
+		return true
+	}
+	return false
+}
+
+// isInfix returns whether op is rendered between its operands.
+func isInfix(op Op) bool {
+	switch op {
+	case OpAdd, OpSub, OpMul, OpDiv, OpMod, OpPow, OpNeg, OpShift:
+		return true
+	}
+	return false
+}
+
+// latex writes the expression to b as LaTeX using leaf to render its leaves.
+func (e *Expr) latex(b *strings.Builder, leaf func(*Expr) string) {
+	operand := func(child *Expr, right bool) {
+		if child.op == OpLeaf {
+			s := leaf(child)
+			if strings.HasPrefix(s, "-") && negativeParens(e.op, right) {
+				s = `\left(` + s + `\right)`
+			}
+			b.WriteString(s)
+			return
+		}
+
+		if fracParens(e.op, child, right) {
+			b.WriteString(`\left(`)
+			child.latex(b, leaf)
+			b.WriteString(`\right)`)
+			return
+		}
+		child.latex(b, leaf)
+	}
+
+	group := func(child *Expr) {
+		b.WriteString(`{`)
+		child.latex(b, leaf)
+		b.WriteString(`}`)
+	}
+
+	switch e.op {
+	case OpLeaf:
+		b.WriteString(leaf(e))
+	case OpAdd, OpSub:
+		operand(e.operands[0], false)
+		b.WriteString(infix(e.op))
+		operand(e.operands[1], true)
+	case OpMul:
+		operand(e.operands[0], false)
+		b.WriteString(` \cdot `)
+		operand(e.operands[1], true)
+	case OpMod:
+		operand(e.operands[0], false)
+		b.WriteString(` \bmod `)
+		operand(e.operands[1], true)
+	case OpDiv:
+		b.WriteString(`\frac`)
+		group(e.operands[0])
+		group(e.operands[1])
+	case OpPow:
+		b.WriteString(`{`)
+		operand(e.operands[0], false)
+		b.WriteString(`}^`)
+		group(e.operands[1])
+	case OpNeg:
+		b.WriteString(`-`)
+		operand(e.operands[0], false)
+	case OpShift:
+		operand(e.operands[0], false)
+		b.WriteString(` \cdot 10^{` + strconv.Itoa(int(e.params[0])) + `}`)
+	case OpAbs:
+		b.WriteString(`\left|`)
+		e.operands[0].latex(b, leaf)
+		b.WriteString(`\right|`)
+	case OpFloor:
+		b.WriteString(`\left\lfloor `)
+		e.operands[0].latex(b, leaf)
+		b.WriteString(` \right\rfloor`)
+	case OpCeil:
+		b.WriteString(`\left\lceil `)
+		e.operands[0].latex(b, leaf)
+		b.WriteString(` \right\rceil`)
+	case OpQuoRem, OpDivRound:
+		sub := strconv.Itoa(int(e.params[0]))
+		if e.isRemainder() {
+			sub += `, \mathrm{` + rem + `}`
+		}
+		b.WriteString(latexFuncs[e.op] + `_{` + sub + `}\left(\frac`)
+		group(e.operands[0])
+		group(e.operands[1])
+		b.WriteString(`\right)`)
+	case OpRound, OpRoundBank, OpRoundCash, OpTruncate:
+		sub := strconv.Itoa(int(e.params[0]))
+		if len(e.params) == 2 {
+			sub += `, \mathrm{` + RoundMode(e.params[1]).String() + `}`
+		}
+		b.WriteString(latexFuncs[e.op] + `_{` + sub + `}\left(`)
+		e.operands[0].latex(b, leaf)
+		b.WriteString(`\right)`)
+	case OpRoundToIncrement:
+		b.WriteString(latexFuncs[e.op] + `_{\mathrm{` + RoundMode(e.params[0]).String() + `}}\left(`)
+		e.operands[0].latex(b, leaf)
+		b.WriteString(comma)
+		e.operands[1].latex(b, leaf)
+		b.WriteString(`\right)`)
+	case OpSqrt:
+		b.WriteString(`\sqrt`)
+		group(e.operands[0])
+	case OpLog:
+		b.WriteString(`\log_`)
+		group(e.operands[1])
+		b.WriteString(`\left(`)
+		e.operands[0].latex(b, leaf)
+		b.WriteString(`\right)`)
+	default:
+		b.WriteString(latexFuncs[e.op] + `\left(`)
+		for i, o := range e.operands {
+			if i > 0 {
+				b.WriteString(comma)
+			}
+			o.latex(b, leaf)
+		}
+		b.WriteString(`\right)`)
+	}
+}
@@ -0,0 +1,83 @@
+package tomath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMathLaTeX(t *testing.T) {
+	d := NewFromFloatWithName("var1", 1.1).
+		Round(1).
+		Add(NewFromFloatWithName("var2", 1)).
+		Add(NewFromFloatWithName("var2", 1)).
+		Div(NewFromFloatWithName("var3", 2)).
+		Mul(NewFromFloatWithName("var4", 2)).
+		SetName("var5")
+
+	vars, formula := d.MathLaTeX()
+	assert.Equal(t, `\frac{\operatorname{round}_{1}\left(\mathrm{var1}\right) + \mathrm{var2} + \mathrm{var2}}{\mathrm{var3}} \cdot \mathrm{var4} = \mathrm{var5}`, vars)
+	assert.Equal(t, `\frac{\operatorname{round}_{1}\left(1.1\right) + 1 + 1}{2} \cdot 2 = 3.1`, formula)
+}
+
+func TestMathLaTeXOperations(t *testing.T) {
+	var1 := NewWithName("var1", -2, 0)
+	var2 := NewWithName("var2", 3, 0)
+	var3 := NewWithName("var3", 4, 0)
+
+	tests := []struct {
+		d       Decimal
+		vars    string
+		formula string
+	}{
+		{var1.Abs(), `\left|\mathrm{var1}\right|`, `\left|-2\right|`},
+		{var1.Floor(), `\left\lfloor \mathrm{var1} \right\rfloor`, `\left\lfloor -2 \right\rfloor`},
+		{var1.Ceil(), `\left\lceil \mathrm{var1} \right\rceil`, `\left\lceil -2 \right\rceil`},
+		{var1.Pow(var2), `{\mathrm{var1}}^{\mathrm{var2}}`, `{\left(-2\right)}^{3}`},
+		{var1.Add(var2).Pow(var3), `{\left(\mathrm{var1} + \mathrm{var2}\right)}^{\mathrm{var3}}`, `{\left(-2 + 3\right)}^{4}`},
+		{var2.Sub(var1.Sub(var3)), `\mathrm{var2} - \left(\mathrm{var1} - \mathrm{var3}\right)`, `3 - \left(-2 - 4\right)`},
+		{var2.Mul(var1), `\mathrm{var2} \cdot \mathrm{var1}`, `3 \cdot \left(-2\right)`},
+		{var2.Mod(var3), `\mathrm{var2} \bmod \mathrm{var3}`, `3 \bmod 4`},
+		{var2.Add(var3).Neg(), `-\left(\mathrm{var2} + \mathrm{var3}\right)`, `-\left(3 + 4\right)`},
+		{var2.Shift(2), `\mathrm{var2} \cdot 10^{2}`, `3 \cdot 10^{2}`},
+		{var2.DivRound(var3, 2), `\operatorname{divRound}_{2}\left(\frac{\mathrm{var2}}{\mathrm{var3}}\right)`, `\operatorname{divRound}_{2}\left(\frac{3}{4}\right)`},
+		{unnamedRemainder(var2, var3, 2), `\operatorname{quoRem}_{2, \mathrm{rem}}\left(\frac{\mathrm{var2}}{\mathrm{var3}}\right)`, `\operatorname{quoRem}_{2, \mathrm{rem}}\left(\frac{3}{4}\right)`},
+		{var2.RoundCash(5), `\operatorname{roundCash}_{5}\left(\mathrm{var2}\right)`, `\operatorname{roundCash}_{5}\left(3\right)`},
+		{Sum(var1, var2, var3), `\sum\left(\mathrm{var1}, \mathrm{var2}, \mathrm{var3}\right)`, `\sum\left(-2, 3, 4\right)`},
+		{Max(var1, var2), `\max\left(\mathrm{var1}, \mathrm{var2}\right)`, `\max\left(-2, 3\right)`},
+		{Min(var1, var2), `\min\left(\mathrm{var1}, \mathrm{var2}\right)`, `\min\left(-2, 3\right)`},
+		{var2.Atan(), `\arctan\left(\mathrm{var2}\right)`, `\arctan\left(3\right)`},
+		{var2.Sqrt(2), `\sqrt{\mathrm{var2}}`, `\sqrt{3}`},
+		{var2.Exp(2), `\exp\left(\mathrm{var2}\right)`, `\exp\left(3\right)`},
+		{var2.Ln(2), `\ln\left(\mathrm{var2}\right)`, `\ln\left(3\right)`},
+		{var2.Log10(2), `\log_{10}\left(\mathrm{var2}\right)`, `\log_{10}\left(3\right)`},
+		{var2.Log(var3, 2), `\log_{\mathrm{var3}}\left(\mathrm{var2}\right)`, `\log_{4}\left(3\right)`},
+		{var2.Div(var3).Asin(), `\arcsin\left(\frac{\mathrm{var2}}{\mathrm{var3}}\right)`, `\arcsin\left(\frac{3}{4}\right)`},
+		{var2.Atan2(var3), `\operatorname{atan2}\left(\mathrm{var2}, \mathrm{var3}\right)`, `\operatorname{atan2}\left(3, 4\right)`},
+		{var2.Tanh(), `\tanh\left(\mathrm{var2}\right)`, `\tanh\left(3\right)`},
+		{var2.RoundWith(1, RoundHalfOdd), `\operatorname{round}_{1, \mathrm{halfOdd}}\left(\mathrm{var2}\right)`, `\operatorname{round}_{1, \mathrm{halfOdd}}\left(3\right)`},
+		{var2.RoundToIncrement(var3, RoundUp), `\operatorname{roundToIncrement}_{\mathrm{up}}\left(\mathrm{var2}, \mathrm{var3}\right)`, `\operatorname{roundToIncrement}_{\mathrm{up}}\left(3, 4\right)`},
+		{var2.Radians(), `\operatorname{radians}\left(\mathrm{var2}\right)`, `\operatorname{radians}\left(3\right)`},
+	}
+
+	for _, test := range tests {
+		vars, formula := test.d.MathLaTeX()
+		assert.Equal(t, test.vars+" = ?", vars)
+		assert.Equal(t, test.formula+" = "+test.d.String(), formula)
+	}
+}
+
+func TestMathLaTeXEscape(t *testing.T) {
+	d := NewWithName(`net_price`, 1, 0).
+		Add(NewWithName(`50% & {#1} $x^2~\y`, 2, 0)).
+		SetName("total_1")
+
+	vars, _ := d.MathLaTeX()
+	assert.Equal(t, `\mathrm{net\_price} + \mathrm{50\% \& \{\#1\} \$x\wedge{}2\sim{}\backslash{}y} = \mathrm{total\_1}`, vars)
+}
+
+// unnamedRemainder returns the unnamed remainder of d / d2 to precision.
+func unnamedRemainder(d, d2 Decimal, precision int32) Decimal {
+	_, r := d.QuoRem(d2, precision)
+	return newOp(r.decimal, OpQuoRem, r.expr.params, d, d2)
+}
@@ -0,0 +1,209 @@
+package tomath
+
+import (
+	"html"
+	"strconv"
+	"strings"
+)
+
+const mathMLNamespace = "http://www.w3.org/1998/Math/MathML"
+
+var mathMLFuncs = map[Op]string{
+	OpAtan:  "arctan",
+	OpAsin:  "arcsin",
+	OpAcos:  "arccos",
+	OpAsinh: "arsinh",
+	OpAcosh: "arcosh",
+	OpAtanh: "artanh",
+}
+
+// MathML returns two Presentation MathML documents representing the formula
+// underlying the decimal. The first uses the decimal names. The second uses the
+// decimal values. Both are followed by an equals sign with the current name and
+// value respectively.
+//
+// Example:
+//
+//     vars, formula := NewFromFloatWithName("var1", 3).
+//         Div(NewFromFloatWithName("var2", 2)).
+//         SetName("var3").
+//         MathML()
+//     // vars:    `<math xmlns="http://www.w3.org/1998/Math/MathML"><mrow><mfrac><mrow><mi>var1</mi></mrow><mrow><mi>var2</mi></mrow></mfrac><mo>=</mo><mi>var3</mi></mrow></math>`
+//     // formula: `<math xmlns="http://www.w3.org/1998/Math/MathML"><mrow><mfrac><mrow><mn>3</mn></mrow><mrow><mn>2</mn></mrow></mfrac><mo>=</mo><mn>1.5</mn></mrow></math>`
+func (d Decimal) MathML() (string, string) {
+	var vars, formula strings.Builder
+	e := d.node()
+
+	vars.WriteString(`<math xmlns="` + mathMLNamespace + `"><mrow>`)
+	e.mathML(&vars, mathMLLeafName)
+	vars.WriteString(`<mo>=</mo>` + mathMLName(d.name) + `</mrow></math>`)
+
+	formula.WriteString(`<math xmlns="` + mathMLNamespace + `"><mrow>`)
+	e.mathML(&formula, mathMLLeafValue)
+	formula.WriteString(`<mo>=</mo>` + mathMLLeafValue(e) + `</mrow></math>`)
+
+	return vars.String(), formula.String()
+}
+
+func mathMLName(name string) string {
+	if name == "" {
+		name = unknown
+	}
+	return `<mi>` + html.EscapeString(name) + `</mi>`
+}
+
+func mathMLLeafName(e *Expr) string {
+	if e.name == "" && e.notation != notationPlain {
+		return mathMLLeafValue(e)
+	}
+	return mathMLName(e.name)
+}
+
+// mathMLLeafValue renders a leaf by its value in its notation, ex:
+// "<mrow><mn>8.25</mn><mo>%</mo></mrow>".
+func mathMLLeafValue(e *Expr) string {
+	v, _ := e.number()
+	switch e.notation {
+	case notationPercent:
+		return `<mrow><mn>` + v.String() + `</mn><mo>%</mo></mrow>`
+	case notationBasisPoints:
+		return `<mrow><mn>` + v.String() + `</mn><mi>bp</mi></mrow>`
+	}
+	return `<mn>` + v.String() + `</mn>`
+}
+
+// mathML writes the expression to b as Presentation MathML using leaf to render
+// its leaves.
+func (e *Expr) mathML(b *strings.Builder, leaf func(*Expr) string) {
+	operand := func(child *Expr, right bool) {
+		if child.op == OpLeaf {
+			s := leaf(child)
+			if (strings.HasPrefix(s, `<mn>-`) || strings.HasPrefix(s, `<mrow><mn>-`)) && negativeParens(e.op, right) {
+				s = `<mrow><mo>(</mo>` + s + `<mo>)</mo></mrow>`
+			}
+			b.WriteString(s)
+			return
+		}
+
+		if fracParens(e.op, child, right) {
+			b.WriteString(`<mrow><mo>(</mo>`)
+			child.mathML(b, leaf)
+			b.WriteString(`<mo>)</mo></mrow>`)
+			return
+		}
+		child.mathML(b, leaf)
+	}
+
+	row := func(child *Expr) {
+		b.WriteString(`<mrow>`)
+		child.mathML(b, leaf)
+		b.WriteString(`</mrow>`)
+	}
+
+	fence := func(open, close string) {
+		b.WriteString(`<mrow><mo>` + open + `</mo>`)
+		e.operands[0].mathML(b, leaf)
+		b.WriteString(`<mo>` + close + `</mo></mrow>`)
+	}
+
+	switch e.op {
+	case OpLeaf:
+		b.WriteString(leaf(e))
+	case OpAdd, OpSub, OpMul, OpMod:
+		b.WriteString(`<mrow>`)
+		operand(e.operands[0], false)
+		b.WriteString(`<mo>` + mathMLInfix(e.op) + `</mo>`)
+		operand(e.operands[1], true)
+		b.WriteString(`</mrow>`)
+	case OpDiv:
+		b.WriteString(`<mfrac>`)
+		row(e.operands[0])
+		row(e.operands[1])
+		b.WriteString(`</mfrac>`)
+	case OpPow:
+		b.WriteString(`<msup><mrow>`)
+		operand(e.operands[0], false)
+		b.WriteString(`</mrow>`)
+		row(e.operands[1])
+		b.WriteString(`</msup>`)
+	case OpNeg:
+		b.WriteString(`<mrow><mo>-</mo>`)
+		operand(e.operands[0], false)
+		b.WriteString(`</mrow>`)
+	case OpShift:
+		b.WriteString(`<mrow>`)
+		operand(e.operands[0], false)
+		b.WriteString(`<mo>&#xD7;</mo><msup><mn>10</mn><mn>` + strconv.Itoa(int(e.params[0])) + `</mn></msup></mrow>`)
+	case OpAbs:
+		fence(`|`, `|`)
+	case OpFloor:
+		fence(`&#x230A;`, `&#x230B;`)
+	case OpCeil:
+		fence(`&#x2308;`, `&#x2309;`)
+	case OpQuoRem, OpDivRound:
+		sub := `<mn>` + strconv.Itoa(int(e.params[0])) + `</mn>`
+		if e.isRemainder() {
+			sub = `<mrow>` + sub + `<mo>,</mo><mi>` + rem + `</mi></mrow>`
+		}
+		b.WriteString(`<mrow><msub><mi>` + e.op.String() + `</mi>` + sub + `</msub><mo>(</mo><mfrac>`)
+		row(e.operands[0])
+		row(e.operands[1])
+		b.WriteString(`</mfrac><mo>)</mo></mrow>`)
+	case OpRound, OpRoundBank, OpRoundCash, OpTruncate:
+		sub := `<mn>` + strconv.Itoa(int(e.params[0])) + `</mn>`
+		if len(e.params) == 2 {
+			sub = `<mrow>` + sub + `<mo>,</mo><mi>` + RoundMode(e.params[1]).String() + `</mi></mrow>`
+		}
+		b.WriteString(`<mrow><msub><mi>` + e.op.String() + `</mi>` + sub + `</msub><mo>(</mo>`)
+		e.operands[0].mathML(b, leaf)
+		b.WriteString(`<mo>)</mo></mrow>`)
+	case OpRoundToIncrement:
+		b.WriteString(`<mrow><msub><mi>` + e.op.String() + `</mi><mi>` + RoundMode(e.params[0]).String() + `</mi></msub><mo>(</mo>`)
+		e.operands[0].mathML(b, leaf)
+		b.WriteString(`<mo>,</mo>`)
+		e.operands[1].mathML(b, leaf)
+		b.WriteString(`<mo>)</mo></mrow>`)
+	case OpSqrt:
+		b.WriteString(`<msqrt>`)
+		e.operands[0].mathML(b, leaf)
+		b.WriteString(`</msqrt>`)
+	case OpLog10:
+		b.WriteString(`<mrow><msub><mi>log</mi><mn>10</mn></msub><mo>(</mo>`)
+		e.operands[0].mathML(b, leaf)
+		b.WriteString(`<mo>)</mo></mrow>`)
+	case OpLog:
+		b.WriteString(`<mrow><msub><mi>log</mi>`)
+		row(e.operands[1])
+		b.WriteString(`</msub><mo>(</mo>`)
+		e.operands[0].mathML(b, leaf)
+		b.WriteString(`<mo>)</mo></mrow>`)
+	default:
+		fn, ok := mathMLFuncs[e.op]
+		if !ok {
+			fn = e.op.String()
+		}
+
+		b.WriteString(`<mrow><mi>` + fn + `</mi><mo>(</mo>`)
+		for i, o := range e.operands {
+			if i > 0 {
+				b.WriteString(`<mo>,</mo>`)
+			}
+			o.mathML(b, leaf)
+		}
+		b.WriteString(`<mo>)</mo></mrow>`)
+	}
+}
+
+func mathMLInfix(op Op) string {
+	switch op {
+	case OpAdd:
+		return `+`
+	case OpSub:
+		return `-`
+	case OpMul:
+		return `&#xD7;`
+	case OpMod:
+		return `mod`
+	}
+	return ""
+}
@@ -0,0 +1,64 @@
+package tomath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMathML(t *testing.T) {
+	d := NewFromFloatWithName("var1", 1.1).
+		Round(1).
+		Add(NewFromFloatWithName("var2", 1)).
+		Div(NewFromFloatWithName("var3", 2)).
+		Mul(NewFromFloatWithName("var4", -2)).
+		SetName("var5")
+
+	vars, formula := d.MathML()
+	assert.Equal(t, `<math xmlns="http://www.w3.org/1998/Math/MathML"><mrow>`+
+		`<mrow><mfrac><mrow><mrow><mrow><msub><mi>round</mi><mn>1</mn></msub><mo>(</mo><mi>var1</mi><mo>)</mo></mrow><mo>+</mo><mi>var2</mi></mrow></mrow><mrow><mi>var3</mi></mrow></mfrac><mo>&#xD7;</mo><mi>var4</mi></mrow>`+
+		`<mo>=</mo><mi>var5</mi></mrow></math>`, vars)
+	assert.Equal(t, `<math xmlns="http://www.w3.org/1998/Math/MathML"><mrow>`+
+		`<mrow><mfrac><mrow><mrow><mrow><msub><mi>round</mi><mn>1</mn></msub><mo>(</mo><mn>1.1</mn><mo>)</mo></mrow><mo>+</mo><mn>1</mn></mrow></mrow><mrow><mn>2</mn></mrow></mfrac><mo>&#xD7;</mo><mrow><mo>(</mo><mn>-2</mn><mo>)</mo></mrow></mrow>`+
+		`<mo>=</mo><mn>-2.1</mn></mrow></math>`, formula)
+}
+
+func TestMathMLOperations(t *testing.T) {
+	var1 := NewWithName("var1", 2, 0)
+	var2 := NewWithName("var2", 3, 0)
+
+	tests := []struct {
+		d    Decimal
+		vars string
+	}{
+		{var1.Abs(), `<mrow><mo>|</mo><mi>var1</mi><mo>|</mo></mrow>`},
+		{var1.Floor(), `<mrow><mo>&#x230A;</mo><mi>var1</mi><mo>&#x230B;</mo></mrow>`},
+		{var1.Ceil(), `<mrow><mo>&#x2308;</mo><mi>var1</mi><mo>&#x2309;</mo></mrow>`},
+		{var1.Sub(var2).Pow(var2), `<msup><mrow><mrow><mo>(</mo><mrow><mi>var1</mi><mo>-</mo><mi>var2</mi></mrow><mo>)</mo></mrow></mrow><mrow><mi>var2</mi></mrow></msup>`},
+		{var1.Neg(), `<mrow><mo>-</mo><mi>var1</mi></mrow>`},
+		{var1.Shift(-2), `<mrow><mi>var1</mi><mo>&#xD7;</mo><msup><mn>10</mn><mn>-2</mn></msup></mrow>`},
+		{var1.Mod(var2), `<mrow><mi>var1</mi><mo>mod</mo><mi>var2</mi></mrow>`},
+		{var1.DivRound(var2, 2), `<mrow><msub><mi>divRound</mi><mn>2</mn></msub><mo>(</mo><mfrac><mrow><mi>var1</mi></mrow><mrow><mi>var2</mi></mrow></mfrac><mo>)</mo></mrow>`},
+		{unnamedRemainder(var1, var2, 2), `<mrow><msub><mi>quoRem</mi><mrow><mn>2</mn><mo>,</mo><mi>rem</mi></mrow></msub><mo>(</mo><mfrac><mrow><mi>var1</mi></mrow><mrow><mi>var2</mi></mrow></mfrac><mo>)</mo></mrow>`},
+		{Avg(var1, var2), `<mrow><mi>avg</mi><mo>(</mo><mi>var1</mi><mo>,</mo><mi>var2</mi><mo>)</mo></mrow>`},
+		{var1.Atan(), `<mrow><mi>arctan</mi><mo>(</mo><mi>var1</mi><mo>)</mo></mrow>`},
+		{var1.Sqrt(2), `<msqrt><mi>var1</mi></msqrt>`},
+		{var1.Ln(2), `<mrow><mi>ln</mi><mo>(</mo><mi>var1</mi><mo>)</mo></mrow>`},
+		{var1.Log10(2), `<mrow><msub><mi>log</mi><mn>10</mn></msub><mo>(</mo><mi>var1</mi><mo>)</mo></mrow>`},
+		{var1.Log(var2, 2), `<mrow><msub><mi>log</mi><mrow><mi>var2</mi></mrow></msub><mo>(</mo><mi>var1</mi><mo>)</mo></mrow>`},
+		{var1.Acosh(), `<mrow><mi>arcosh</mi><mo>(</mo><mi>var1</mi><mo>)</mo></mrow>`},
+		{var1.RoundWith(1, RoundUp), `<mrow><msub><mi>round</mi><mrow><mn>1</mn><mo>,</mo><mi>up</mi></mrow></msub><mo>(</mo><mi>var1</mi><mo>)</mo></mrow>`},
+		{var1.RoundToIncrement(var2, RoundFloor), `<mrow><msub><mi>roundToIncrement</mi><mi>floor</mi></msub><mo>(</mo><mi>var1</mi><mo>,</mo><mi>var2</mi><mo>)</mo></mrow>`},
+		{var1.Sinh(), `<mrow><mi>sinh</mi><mo>(</mo><mi>var1</mi><mo>)</mo></mrow>`},
+	}
+
+	for _, test := range tests {
+		vars, _ := test.d.MathML()
+		assert.Equal(t, `<math xmlns="http://www.w3.org/1998/Math/MathML"><mrow>`+test.vars+`<mo>=</mo><mi>?</mi></mrow></math>`, vars)
+	}
+}
+
+func TestMathMLEscape(t *testing.T) {
+	vars, _ := NewWithName("a<b & c", 1, 0).MathML()
+	assert.Equal(t, `<math xmlns="http://www.w3.org/1998/Math/MathML"><mrow><mi>a&lt;b &amp; c</mi><mo>=</mo><mi>a&lt;b &amp; c</mi></mrow></math>`, vars)
+}
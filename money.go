@@ -0,0 +1,198 @@
+package tomath
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// Money is an amount in a Currency. Its arithmetic is traced like the one of a
+// Decimal, rejects amounts in different currencies and rounds the results to
+// the minor units of the currency, half away from zero unless WithRounding()
+// selects another RoundMode.
+//
+// Example:
+//
+//     price, _ := NewMoney("price", "12.5", "USD")
+//     fee, _ := NewMoney("fee", "1", "USD")
+//     total, _ := price.Add(fee)
+//     total.SetName("total").Math()
+//     // output: "price (USD 12.50) + fee (USD 1.00) = total (USD 13.50)"
+//
+// The zero value has no currency and cannot be added to any amount.
+type Money struct {
+	amount   Decimal
+	currency Currency
+	rounding RoundMode
+}
+
+// NewMoney returns the amount value named name in the currency with the ISO
+// 4217 code code, rounded to its minor units.
+func NewMoney(name, value, code string) (Money, error) {
+	d, err := NewFromStringWithName(name, value)
+	if err != nil {
+		return Money{}, err
+	}
+	return NewMoneyFromDecimal(d, code)
+}
+
+// NewMoneyFromDecimal returns the amount d in the currency with the ISO 4217
+// code code, rounded to its minor units.
+func NewMoneyFromDecimal(d Decimal, code string) (Money, error) {
+	c, ok := LookupCurrency(code)
+	if !ok {
+		return Money{}, errors.New("tomath: unknown currency " + code)
+	}
+
+	if e := d.node(); e.op == OpLeaf {
+		leaf := *e
+		leaf.currency = code
+		d.expr = &leaf
+	}
+
+	return Money{currency: c}.with(d), nil
+}
+
+// Amount returns the amount as a Decimal.
+func (m Money) Amount() Decimal {
+	return m.amount
+}
+
+// Currency returns the currency of the amount.
+func (m Money) Currency() Currency {
+	return m.currency
+}
+
+// WithRounding returns m whose results are rounded to the minor units of the
+// currency in mode.
+func (m Money) WithRounding(mode RoundMode) Money {
+	m.rounding = mode
+	return m
+}
+
+// SetName sets the name of the amount.
+func (m Money) SetName(name string) Money {
+	m.amount = m.amount.SetName(name)
+	return m
+}
+
+// GetName gets the name of the amount.
+func (m Money) GetName() string {
+	return m.amount.name
+}
+
+// Add returns m + m2. It returns an error if m2 is in another currency or if
+// either amount has no currency.
+func (m Money) Add(m2 Money) (Money, error) {
+	if err := m.checkCurrencies(m2); err != nil {
+		return Money{}, err
+	}
+	if m.currency != m2.currency {
+		return Money{}, errors.New("tomath: cannot add " + m2.currency.Code + " to " + m.currency.Code)
+	}
+	return m.with(m.amount.Add(m2.amount)), nil
+}
+
+// Sub returns m - m2. It returns an error if m2 is in another currency or if
+// either amount has no currency.
+func (m Money) Sub(m2 Money) (Money, error) {
+	if err := m.checkCurrencies(m2); err != nil {
+		return Money{}, err
+	}
+	if m.currency != m2.currency {
+		return Money{}, errors.New("tomath: cannot subtract " + m2.currency.Code + " from " + m.currency.Code)
+	}
+	return m.with(m.amount.Sub(m2.amount)), nil
+}
+
+// checkCurrencies returns an error if m or m2 has no currency, as the zero
+// value does.
+func (m Money) checkCurrencies(m2 Money) error {
+	if m.currency.Code == "" || m2.currency.Code == "" {
+		return errors.New("tomath: amount without currency")
+	}
+	return nil
+}
+
+// Neg returns -m.
+func (m Money) Neg() Money {
+	return m.with(m.amount.Neg())
+}
+
+// Abs returns the absolute value of m.
+func (m Money) Abs() Money {
+	return m.with(m.amount.Abs())
+}
+
+// Mul returns m * d rounded to the minor units of the currency.
+func (m Money) Mul(d Decimal) Money {
+	return m.with(m.amount.Mul(d))
+}
+
+// Div returns m / d rounded once to the minor units of the currency. The
+// division is rendered with its Context, ex: "div[prec=2](price, quantity)".
+func (m Money) Div(d Decimal) Money {
+	c := Context{Precision: m.currency.MinorUnits, Rounding: m.rounding}
+	return m.with(c.Div(m.amount, d))
+}
+
+// String returns the currency code followed by the amount with all its minor
+// units, ex: "USD 12.50".
+func (m Money) String() string {
+	return m.currency.format(m.amount.decimal)
+}
+
+// Math returns the formula underlying the amount with the names of its values
+// followed by their value, amounts in their currency, ex:
+// "price (USD 12.50) * quantity (3) = total (USD 37.50)".
+func (m Money) Math() string {
+	var b strings.Builder
+	m.amount.node().render(&b, moneyLeaf, MathOptions{})
+	return b.String() + equal + annotate(m.amount.name, m.String())
+}
+
+// with returns d rounded to the minor units of the currency as an amount of
+// the currency of m. The round is only recorded when it changes d.
+func (m Money) with(d Decimal) Money {
+	places := m.currency.MinorUnits
+	if !d.decimal.Round(places).Equal(d.decimal) {
+		if m.rounding == RoundHalfUp {
+			d = d.Round(places)
+		} else {
+			d = d.RoundWith(places, m.rounding)
+		}
+	}
+
+	m.amount = d
+	return m
+}
+
+// format renders v in the currency, padded to its minor units.
+func (c Currency) format(v decimal.Decimal) string {
+	s := v.String()
+	if v.Round(c.MinorUnits).Equal(v) {
+		s = v.StringFixed(c.MinorUnits)
+	}
+	return c.Code + " " + s
+}
+
+// moneyLeaf renders a leaf by its name followed by its value, in its currency
+// if any.
+func moneyLeaf(e *Expr) string {
+	value := e.value.String()
+	if c, ok := LookupCurrency(e.currency); ok {
+		value = c.format(e.value)
+	}
+	return annotate(e.name, value)
+}
+
+// annotate renders a value by its name followed by the value in parentheses,
+// or by the value alone when it has no name. The names are not quoted since
+// the annotated formula is not read back by Parse().
+func annotate(name, value string) string {
+	if name == "" {
+		return value
+	}
+	return name + " (" + value + ")"
+}
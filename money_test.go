@@ -0,0 +1,115 @@
+package tomath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func requireMoney(t *testing.T, name, value, code string) Money {
+	t.Helper()
+	m, err := NewMoney(name, value, code)
+	require.NoError(t, err)
+	return m
+}
+
+func TestMoneyMath(t *testing.T) {
+	price := requireMoney(t, "price", "12.5", "USD")
+	fee := requireMoney(t, "fee", "1", "USD")
+
+	total, err := price.Add(fee)
+	require.NoError(t, err)
+	total = total.SetName("total")
+	assert.Equal(t, "USD 13.50", total.String())
+	assert.Equal(t, "price (USD 12.50) + fee (USD 1.00) = total (USD 13.50)", total.Math())
+
+	net, err := total.Sub(requireMoney(t, "discount", "2.25", "USD"))
+	require.NoError(t, err)
+	assert.Equal(t, "price (USD 12.50) + fee (USD 1.00) - discount (USD 2.25) = USD 11.25", net.Math())
+
+	vars, formula := net.Amount().Math()
+	assert.Equal(t, "price + fee - discount = ?", vars)
+	assert.Equal(t, "12.5 + 1 - 2.25 = 11.25", formula)
+}
+
+func TestMoneyMixedCurrencies(t *testing.T) {
+	usd := requireMoney(t, "price", "12.5", "USD")
+	eur := requireMoney(t, "fee", "1", "EUR")
+
+	_, err := usd.Add(eur)
+	assert.EqualError(t, err, "tomath: cannot add EUR to USD")
+
+	_, err = usd.Sub(eur)
+	assert.EqualError(t, err, "tomath: cannot subtract EUR from USD")
+
+	_, err = usd.Add(Money{})
+	assert.EqualError(t, err, "tomath: amount without currency")
+}
+
+func TestMoneyZeroValue(t *testing.T) {
+	usd := requireMoney(t, "price", "12.5", "USD")
+
+	_, err := Money{}.Add(Money{})
+	assert.EqualError(t, err, "tomath: amount without currency")
+
+	_, err = Money{}.Sub(Money{})
+	assert.EqualError(t, err, "tomath: amount without currency")
+
+	_, err = Money{}.Add(usd)
+	assert.EqualError(t, err, "tomath: amount without currency")
+
+	_, err = usd.Sub(Money{})
+	assert.EqualError(t, err, "tomath: amount without currency")
+}
+
+func TestMoneyRounding(t *testing.T) {
+	price := requireMoney(t, "price", "19.99", "USD")
+	rate := NewFromFloatWithName("rate", 0.0825)
+
+	tax := price.Mul(rate).SetName("tax")
+	assert.Equal(t, "USD 1.65", tax.String())
+	assert.Equal(t, "round(2)(price (USD 19.99) * rate (0.0825)) = tax (USD 1.65)", tax.Math())
+
+	tax = price.WithRounding(RoundDown).Mul(rate)
+	assert.Equal(t, "round(2, down)(price (USD 19.99) * rate (0.0825)) = USD 1.64", tax.Math())
+
+	share := price.Div(NewFromIntWithName("people", 3)).SetName("share")
+	assert.Equal(t, "div[prec=2](price (USD 19.99), people (3)) = share (USD 6.66)", share.Math())
+
+	share = price.WithRounding(RoundCeiling).Div(NewFromIntWithName("people", 3))
+	assert.Equal(t, "USD 6.67", share.String())
+
+	exact := price.Mul(NewFromIntWithName("quantity", 2))
+	assert.Equal(t, "price (USD 19.99) * quantity (2) = USD 39.98", exact.Math())
+
+	yen := requireMoney(t, "price", "1234.5", "JPY")
+	assert.Equal(t, "JPY 1235", yen.String())
+	assert.Equal(t, "round(0)(price (JPY 1234.5)) = JPY 1235", yen.Math())
+
+	dinar := requireMoney(t, "price", "1.5", "KWD")
+	assert.Equal(t, "price (KWD 1.500) = price (KWD 1.500)", dinar.Math())
+	assert.Equal(t, "KWD -1.500", dinar.Neg().String())
+	assert.Equal(t, "KWD 1.500", dinar.Neg().Abs().String())
+}
+
+func TestNewMoney(t *testing.T) {
+	_, err := NewMoney("price", "1", "ABC")
+	assert.EqualError(t, err, "tomath: unknown currency ABC")
+
+	_, err = NewMoney("price", "abc", "USD")
+	assert.Error(t, err)
+
+	m, err := NewMoneyFromDecimal(NewFromFloat(2.5), "EUR")
+	require.NoError(t, err)
+	assert.Equal(t, "", m.GetName())
+	assert.Equal(t, Currency{Code: "EUR", MinorUnits: 2}, m.Currency())
+	assert.Equal(t, "EUR 2.50 = EUR 2.50", m.Math())
+	assert.Equal(t, "EUR", m.Amount().Expr().Currency())
+
+	total := NewFromFloatWithName("a", 1).Add(NewFromFloatWithName("b", 2)).SetName("c")
+	m, err = NewMoneyFromDecimal(total, "EUR")
+	require.NoError(t, err)
+	assert.Equal(t, "c", m.GetName())
+	assert.Equal(t, "a (1) + b (2) = c (EUR 3.00)", m.Math())
+}
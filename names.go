@@ -0,0 +1,93 @@
+package tomath
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/shopspring/decimal"
+)
+
+const quote = "`"
+
+// ValidateName returns an error when name cannot be rendered and parsed back as
+// the name of a Decimal: when it is empty, is not valid UTF-8 or contains
+// control characters such as newlines.
+//
+// Any other name is valid. Unicode identifiers such as "Größe", dotted names
+// such as "invoice.line[3].price" and the names of rates such as
+// "rate[EURUSD@2026-10-15]" are rendered as is. Names which would be read
+// as an operator, a number or "?" such as "net-price" are quoted with backticks
+// by Math(), ex: "`net-price` * quantity = total", and accepted quoted by
+// Parse(). A backtick inside a quoted name is doubled.
+func ValidateName(name string) error {
+	switch {
+	case name == "":
+		return errors.New("tomath: empty name")
+	case !utf8.ValidString(name):
+		return errors.New("tomath: invalid UTF-8 in name " + strconv.Quote(name))
+	case strings.IndexFunc(name, unicode.IsControl) >= 0:
+		return errors.New("tomath: control character in name " + strconv.Quote(name))
+	}
+	return nil
+}
+
+// SetNameStrict sets the name of the Decimal like SetName() does, after
+// checking it with ValidateName().
+func (d Decimal) SetNameStrict(name string) (Decimal, error) {
+	if err := ValidateName(name); err != nil {
+		return d, err
+	}
+	return d.SetName(name), nil
+}
+
+// quoteName returns name as it is written in a formula, quoted with backticks
+// when it would not be read back as the same name.
+func quoteName(name string) string {
+	if !needsQuotes(name) {
+		return name
+	}
+	return quote + strings.Replace(name, quote, quote+quote, -1) + quote
+}
+
+func needsQuotes(name string) bool {
+	if n := rateNameLen(name); n > 0 && n == len(name) {
+		return false
+	}
+	if name == unknown || strings.ContainsAny(name, operators+quote) || strings.Contains(name, contextStart) || strings.IndexFunc(name, unicode.IsSpace) >= 0 {
+		return true
+	}
+	for u := range unicodeOperators {
+		if strings.Contains(name, u) {
+			return true
+		}
+	}
+	if _, _, ok := parseNotation(name); ok {
+		return true
+	}
+	_, err := decimal.NewFromString(name)
+	return err == nil
+}
+
+// rateNameLen returns the length of the name of a Rate starting s, ex:
+// "rate[EURUSD@2026-10-15]", which is read as a single name despite the
+// dashes of its date, or 0.
+func rateNameLen(s string) int {
+	const prefix, pair = "rate[", 6
+	n := len(prefix) + pair + 1 + len(rateDate) + 1
+	if len(s) < n || !strings.HasPrefix(s, prefix) || s[n-len(rateDate)-2] != '@' || s[n-1] != ']' {
+		return 0
+	}
+	for _, c := range s[len(prefix) : len(prefix)+pair] {
+		if c < 'A' || c > 'Z' {
+			return 0
+		}
+	}
+	if _, err := time.Parse(rateDate, s[n-len(rateDate)-1:n-1]); err != nil {
+		return 0
+	}
+	return n
+}
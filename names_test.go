@@ -0,0 +1,97 @@
+package tomath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateName(t *testing.T) {
+	for _, name := range []string{"var1", "net-price", "Größe", "数量", "invoice.line[3].price", "a `quoted` name", "?", "12"} {
+		assert.NoError(t, ValidateName(name), name)
+	}
+
+	tests := map[string]string{
+		"":         "tomath: empty name",
+		"\xff":     `tomath: invalid UTF-8 in name "\xff"`,
+		"net\nsum": `tomath: control character in name "net\nsum"`,
+	}
+	for name, want := range tests {
+		assert.EqualError(t, ValidateName(name), want)
+	}
+}
+
+func TestSetNameStrict(t *testing.T) {
+	d, err := NewFromInt(1).SetNameStrict("invoice.line[3].price")
+	require.NoError(t, err)
+	assert.Equal(t, "invoice.line[3].price", d.GetName())
+
+	d, err = d.SetNameStrict("")
+	assert.EqualError(t, err, "tomath: empty name")
+	assert.Equal(t, "invoice.line[3].price", d.GetName())
+}
+
+func TestMathQuotesNames(t *testing.T) {
+	tests := map[string]string{
+		"var1":                     "var1",
+		"Größe":                    "Größe",
+		"invoice.line[3].price":    "invoice.line[3].price",
+		"net-price":                "`net-price`",
+		"a=b":                      "`a=b`",
+		"net price":                "`net price`",
+		"price×2":                  "`price×2`",
+		"12":                       "`12`",
+		"?":                        "`?`",
+		"a`b":                      "`a``b`",
+		"rate[EURUSD@2026-10-15]":  "rate[EURUSD@2026-10-15]",
+		"rate[EURUSD@2026-10-15]x": "`rate[EURUSD@2026-10-15]x`",
+		"rate[EURUSD@2026-13-15]":  "`rate[EURUSD@2026-13-15]`",
+	}
+
+	for name, want := range tests {
+		vars, _ := NewFromIntWithName(name, 1).Add(NewFromIntWithName("fee", 2)).SetName(name).Math()
+		assert.Equal(t, want+" + fee = "+want, vars)
+	}
+}
+
+func TestParseQuotedNames(t *testing.T) {
+	d := NewFromFloatWithName("net-price", 12.5).
+		Mul(NewFromFloatWithName("invoice.line[3].quantity", 3)).
+		Sub(NewFromFloatWithName("Rabatt (%)", 2)).
+		Add(NewFromFloatWithName("a`b", 1)).
+		SetName("total = net")
+
+	vars, formula := d.Math()
+	assert.Equal(t, "`net-price` * invoice.line[3].quantity - `Rabatt (%)` + `a``b` = `total = net`", vars)
+
+	p, err := Parse(vars, leaves(d))
+	require.NoError(t, err)
+	pvars, pformula := p.Math()
+	assert.Equal(t, vars, pvars)
+	assert.Equal(t, formula, pformula)
+	assert.Equal(t, "total = net", p.GetName())
+}
+
+func TestParseQuotedNameErrors(t *testing.T) {
+	bindings := map[string]Decimal{"a": NewFromInt(1)}
+	tests := map[string]string{
+		"a + `b":        "tomath: unterminated quoted name at offset 4",
+		"a = `b":        "tomath: unterminated quoted name at offset 4",
+		"a + `b`":       "tomath: unbound name \"b\" at offset 4",
+		"a = b c":       "tomath: unexpected \"c\" at offset 6",
+		"a = )":         "tomath: expected a name at offset 4",
+		"a = - b":       "tomath: expected a number at offset 6",
+		"`a`(1)":        "tomath: unexpected \"(\" at offset 3",
+		"a = `b` = `c`": "tomath: unexpected \"=\" at offset 8",
+	}
+
+	for expr, want := range tests {
+		_, err := Parse(expr, bindings)
+		assert.EqualError(t, err, want, expr)
+	}
+
+	d, err := Parse("a + a = -1.5", bindings)
+	require.NoError(t, err)
+	assert.Equal(t, "", d.GetName())
+}
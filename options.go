@@ -0,0 +1,420 @@
+package tomath
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// Parens selects how MathWith() parenthesizes the operands of binary operations.
+type Parens int
+
+const (
+	// ParensStandard only adds the parentheses required by the precedence and
+	// associativity of the operations so that Parse() rebuilds the same tree.
+	// This is the output of Math().
+	ParensStandard Parens = iota
+	// ParensMinimal also drops the parentheses around the right operand of
+	// additions and multiplications when regrouping them does not change the
+	// result, ex: "a + b - c" instead of "a + (b - c)".
+	ParensMinimal
+	// ParensFull wraps every operand which is itself a binary operation, ex:
+	// "(a * b) + (c / d)".
+	ParensFull
+)
+
+// Placeholder selects how MathWith() names the values which have no name.
+type Placeholder int
+
+const (
+	// PlaceholderUnknown names every unnamed value "?". This is the output of
+	// Math().
+	PlaceholderUnknown Placeholder = iota
+	// PlaceholderGenerate names the unnamed values t1, t2, ... in order of
+	// appearance, the result last. A value used several times keeps its name
+	// and names already used by the formula are skipped. StepsWith(), DOTWith()
+	// and MermaidWith() go on naming the unnamed intermediate results, after the
+	// result, in the order they are evaluated so that the values they share
+	// with MathWith() have the same names.
+	PlaceholderGenerate
+	// PlaceholderValue renders unnamed leaves by their value. An unnamed result
+	// is still named "?". Steps and graphs render unnamed values by their value
+	// anyway.
+	PlaceholderValue
+	// PlaceholderError makes MathWith() return an *UnnamedError when any value
+	// of the formula, the result included, has no name. StepsWith(), DOTWith()
+	// and MermaidWith() also require the intermediate results to be named.
+	PlaceholderError
+)
+
+// UnnamedError lists the unnamed values of a formula rendered with
+// PlaceholderError.
+type UnnamedError struct {
+	// Values holds the unnamed leaves in order of appearance.
+	Values []decimal.Decimal
+	// Intermediates holds the unnamed intermediate results in the order they
+	// are evaluated. Only StepsWith(), DOTWith() and MermaidWith() set it.
+	Intermediates []decimal.Decimal
+	// Result is set when the result has no name.
+	Result bool
+}
+
+func (e *UnnamedError) Error() string {
+	var groups []string
+	if len(e.Values) > 0 {
+		groups = append(groups, "values "+joinValues(e.Values))
+	}
+	if len(e.Intermediates) > 0 {
+		groups = append(groups, "intermediates "+joinValues(e.Intermediates))
+	}
+	if e.Result {
+		groups = append(groups, "result")
+	}
+	return "tomath: unnamed " + strings.Join(groups, " and ")
+}
+
+func joinValues(values []decimal.Decimal) string {
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = v.String()
+	}
+	return strings.Join(s, comma)
+}
+
+// MathOptions controls the output of MathWith(). The zero value renders the
+// same output as Math().
+type MathOptions struct {
+	Parens Parens
+	// Unicode spells multiplications and divisions as "×" and "÷" instead of
+	// "*" and "/".
+	Unicode bool
+	// Fixed formats every value with StringFixed(Places).
+	Fixed  bool
+	Places int32
+	// Separator groups the integer digits of every value by thousands, ex: ",".
+	Separator string
+	// Unnamed names the values which have no name.
+	Unnamed Placeholder
+	// Collapse renders the operations named in any of the scopes by their name
+	// instead of their operands. Every scope is expanded by default.
+	Collapse []Scope
+	// Expand renders the decimals resolved to any of the names by the
+	// computation Resolve() hid, grouped in parentheses, ex:
+	// "var5 + (var6 * OneHundred) = var7" instead of
+	// "var5 + var6TimesOneHundred = var7".
+	Expand []string
+	// ExpandDepth expands the resolved decimals nested in fewer than
+	// ExpandDepth other resolved decimals, the result included. A negative
+	// depth expands every resolved decimal.
+	ExpandDepth int
+}
+
+// MathWith returns two strings representing the formula underlying the decimal
+// the same way Math() does, formatted according to opts. It returns an error
+// when opts.Parens or opts.Unnamed is not one of their constants, or an
+// *UnnamedError when opts.Unnamed is PlaceholderError and a value has no name.
+//
+// Example:
+//
+//     vars, formula, err := NewFromFloatWithName("price", 1234.5).
+//         Mul(NewFromFloatWithName("quantity", 3)).
+//         SetName("total").
+//         MathWith(MathOptions{Unicode: true, Fixed: true, Places: 2, Separator: ","})
+//     // vars:    "price × quantity = total"
+//     // formula: "1,234.50 × 3.00 = 3,703.50"
+//
+// Formulas rendered with ParensMinimal or with formatted values may not be read
+// back by Parse().
+func (d Decimal) MathWith(opts MathOptions) (string, string, error) {
+	switch opts.Parens {
+	case ParensStandard, ParensMinimal, ParensFull:
+	default:
+		return "", "", errors.New("tomath: unknown parens mode " + strconv.Itoa(int(opts.Parens)))
+	}
+
+	e := collapse(opts.expand(d.node(), 0), opts.Collapse)
+	leaf, name, err := opts.names(e, d.name)
+	if err != nil {
+		return "", "", err
+	}
+
+	value := func(e *Expr) string {
+		return opts.formatLiteral(e)
+	}
+
+	var vars, formula strings.Builder
+	e.render(&vars, leaf, opts)
+	e.render(&formula, value, opts)
+
+	return vars.String() + equal + name,
+		formula.String() + equal + opts.formatLiteral(d.node()),
+		nil
+}
+
+// unnamedValues lists the values of an expression which have no name.
+type unnamedValues struct {
+	// leaves holds the unnamed leaves in order of appearance. The percentages
+	// and basis points, rendered by their value, are not listed.
+	leaves []*Expr
+	// ops holds the unnamed operations other than the result in the order they
+	// are evaluated.
+	ops []*Expr
+	// used holds the names of the expression and of its result.
+	used map[string]bool
+	// result is the name of the result.
+	result string
+}
+
+// findUnnamed lists the unnamed values of e whose result is named result.
+func findUnnamed(e *Expr, result string) unnamedValues {
+	u := unnamedValues{used: map[string]bool{result: true}, result: result}
+	seen := map[*Expr]bool{}
+	var walk func(x *Expr)
+	walk = func(x *Expr) {
+		if seen[x] {
+			return
+		}
+		seen[x] = true
+		u.used[x.name] = true
+
+		if x.op == OpLeaf && x.name == "" && x.notation == notationPlain {
+			u.leaves = append(u.leaves, x)
+		}
+		for _, o := range x.operands {
+			walk(o)
+		}
+		if x.op != OpLeaf && x.name == "" && x != e {
+			u.ops = append(u.ops, x)
+		}
+	}
+	walk(e)
+
+	return u
+}
+
+// generate returns the names PlaceholderGenerate gives to the unnamed leaves,
+// to the operations as well when intermediates is set, and to the result when
+// it is unnamed.
+func (u unnamedValues) generate(intermediates bool) (map[*Expr]string, string) {
+	n := 0
+	next := func() string {
+		for {
+			n++
+			if name := "t" + strconv.Itoa(n); !u.used[name] {
+				return name
+			}
+		}
+	}
+
+	generated := map[*Expr]string{}
+	for _, l := range u.leaves {
+		generated[l] = next()
+	}
+	result := u.result
+	if result == "" {
+		result = next()
+	}
+	if intermediates {
+		for _, o := range u.ops {
+			generated[o] = next()
+		}
+	}
+
+	return generated, result
+}
+
+// error returns the *UnnamedError listing the unnamed values, the
+// intermediate results included when intermediates is set, or nil.
+func (u unnamedValues) error(result string, intermediates bool) error {
+	err := &UnnamedError{Result: result == ""}
+	for _, l := range u.leaves {
+		err.Values = append(err.Values, l.value)
+	}
+	if intermediates {
+		for _, o := range u.ops {
+			err.Intermediates = append(err.Intermediates, o.value)
+		}
+	}
+
+	if err.Values == nil && err.Intermediates == nil && !err.Result {
+		return nil
+	}
+	return err
+}
+
+// names returns the function rendering the leaves of e by name and the name of
+// the result according to opts.Unnamed.
+func (opts MathOptions) names(e *Expr, result string) (func(*Expr) string, string, error) {
+	u := findUnnamed(e, result)
+
+	name := unknown
+	if result != "" {
+		name = quoteName(result)
+	}
+
+	switch opts.Unnamed {
+	case PlaceholderUnknown:
+	case PlaceholderGenerate:
+		generated, generatedResult := u.generate(false)
+		if result == "" {
+			name = generatedResult
+		}
+		return func(e *Expr) string {
+			if name, ok := generated[e]; ok {
+				return name
+			}
+			return leafName(e)
+		}, name, nil
+	case PlaceholderValue:
+		return func(e *Expr) string {
+			if e.name == "" {
+				return opts.formatLiteral(e)
+			}
+			return leafName(e)
+		}, name, nil
+	case PlaceholderError:
+		if err := u.error(result, false); err != nil {
+			return nil, "", err
+		}
+	default:
+		return nil, "", unknownPlaceholder(opts.Unnamed)
+	}
+
+	return leafName, name, nil
+}
+
+// valueNames returns the function naming the values of e, whose result is
+// named result, in steps and graphs according to unnamed. Unnamed values are
+// named "".
+func valueNames(e *Expr, result string, unnamed Placeholder) (func(*Expr) string, error) {
+	u := findUnnamed(e, result)
+
+	switch unnamed {
+	case PlaceholderUnknown, PlaceholderValue:
+	case PlaceholderGenerate:
+		generated, generatedResult := u.generate(true)
+		return func(x *Expr) string {
+			if name, ok := generated[x]; ok {
+				return name
+			}
+			if x == e && result == "" {
+				return generatedResult
+			}
+			return x.name
+		}, nil
+	case PlaceholderError:
+		if err := u.error(result, true); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, unknownPlaceholder(unnamed)
+	}
+
+	return exprName, nil
+}
+
+func exprName(e *Expr) string {
+	return e.name
+}
+
+func unknownPlaceholder(p Placeholder) error {
+	return errors.New("tomath: unknown placeholder " + strconv.Itoa(int(p)))
+}
+
+// expand returns e in which the resolved leaves selected by opts.Expand and
+// opts.ExpandDepth are replaced by the computation they hide. depth is the
+// number of expanded leaves enclosing e.
+func (opts MathOptions) expand(e *Expr, depth int) *Expr {
+	if len(opts.Expand) == 0 && opts.ExpandDepth == 0 {
+		return e
+	}
+
+	if e.op == OpLeaf {
+		if e.resolved == nil || !opts.expands(e.name, depth) {
+			return e
+		}
+		x := *opts.expand(e.resolved, depth+1)
+		x.expanded = true
+		return &x
+	}
+
+	c := *e
+	c.operands = make([]*Expr, len(e.operands))
+	for i, o := range e.operands {
+		c.operands[i] = opts.expand(o, depth)
+	}
+
+	return &c
+}
+
+// expands returns whether the resolved leaf named name enclosed in depth
+// expanded leaves is expanded.
+func (opts MathOptions) expands(name string, depth int) bool {
+	if opts.ExpandDepth < 0 || depth < opts.ExpandDepth {
+		return true
+	}
+	for _, n := range opts.Expand {
+		if n == name && name != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// infix returns the symbol, surrounded by its spacing, of a binary operation.
+func (opts MathOptions) infix(op Op) string {
+	if opts.Unicode {
+		switch op {
+		case OpMul:
+			return " × "
+		case OpDiv:
+			return " ÷ "
+		}
+	}
+	return infix(op)
+}
+
+// format renders a value.
+func (opts MathOptions) format(v decimal.Decimal) string {
+	s := v.String()
+	if opts.Fixed {
+		s = v.StringFixed(opts.Places)
+	}
+	if opts.Separator != "" {
+		s = groupThousands(s, opts.Separator)
+	}
+	return s
+}
+
+// formatLiteral renders the value of a node in its notation.
+func (opts MathOptions) formatLiteral(e *Expr) string {
+	v, suffix := e.number()
+	return opts.format(v) + suffix
+}
+
+// groupThousands inserts sep between every group of three integer digits of s.
+func groupThousands(s, sep string) string {
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+
+	integer, fraction := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		integer, fraction = s[:i], s[i:]
+	}
+
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, c := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteString(sep)
+		}
+		b.WriteRune(c)
+	}
+	b.WriteString(fraction)
+
+	return b.String()
+}
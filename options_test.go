@@ -0,0 +1,208 @@
+package tomath
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMathWithDefault(t *testing.T) {
+	d := NewFromFloatWithName("var1", 1.1).
+		Round(1).
+		Add(NewFromFloatWithName("var2", 1)).
+		Div(NewFromFloatWithName("var3", 2)).
+		SetName("var4")
+
+	vars, formula := d.Math()
+	wvars, wformula, err := d.MathWith(MathOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, vars, wvars)
+	assert.Equal(t, formula, wformula)
+}
+
+func TestMathWithParens(t *testing.T) {
+	a := NewWithName("a", 7, 0)
+	b := NewWithName("b", 3, 0)
+	c := NewWithName("c", 2, 0)
+	e := NewWithName("e", 5, 0)
+
+	tests := []struct {
+		d                       Decimal
+		minimal, standard, full string
+	}{
+		{a.Add(b).Add(c), "a + b + c = ?", "a + b + c = ?", "(a + b) + c = ?"},
+		{a.Add(b.Add(c)), "a + b + c = ?", "a + (b + c) = ?", "a + (b + c) = ?"},
+		{a.Add(b.Sub(c)), "a + b - c = ?", "a + (b - c) = ?", "a + (b - c) = ?"},
+		{a.Sub(b.Add(c)), "a - (b + c) = ?", "a - (b + c) = ?", "a - (b + c) = ?"},
+		{a.Mul(b.Mul(c)), "a * b * c = ?", "a * (b * c) = ?", "a * (b * c) = ?"},
+		{a.Mul(b.Div(c)), "a * (b / c) = ?", "a * (b / c) = ?", "a * (b / c) = ?"},
+		{a.Mul(b).Add(c.Div(e)), "a * b + c / e = ?", "a * b + c / e = ?", "(a * b) + (c / e) = ?"},
+		{a.Pow(b).Pow(c), "(a^b)^c = ?", "(a^b)^c = ?", "(a^b)^c = ?"},
+		{a.Pow(b.Pow(c)), "a^b^c = ?", "a^b^c = ?", "a^(b^c) = ?"},
+		{a.Mul(b).DivRound(c, 2), "divRound(2)(a * b / c) = ?", "divRound(2)(a * b / c) = ?", "divRound(2)((a * b) / c) = ?"},
+		{a.Add(b).Round(1), "round(1)(a + b) = ?", "round(1)(a + b) = ?", "round(1)(a + b) = ?"},
+	}
+
+	for _, test := range tests {
+		for mode, want := range map[Parens]string{
+			ParensMinimal:  test.minimal,
+			ParensStandard: test.standard,
+			ParensFull:     test.full,
+		} {
+			vars, formula, err := test.d.MathWith(MathOptions{Parens: mode})
+			require.NoError(t, err)
+			assert.Equal(t, want, vars)
+
+			p, err := Parse(formula, nil)
+			require.NoError(t, err, formula)
+			assert.Equal(t, test.d.String(), p.String(), formula)
+		}
+	}
+}
+
+func TestMathWithUnicode(t *testing.T) {
+	var1 := NewFromFloatWithName("var1", 4.333)
+	var2 := NewFromFloatWithName("var2", 2.7)
+	d := var1.Mul(var2).Add(var1.Div(var2)).Sub(var1.DivRound(var2, 2))
+
+	vars, formula, err := d.MathWith(MathOptions{Unicode: true})
+	require.NoError(t, err)
+	assert.Equal(t, "var1 × var2 + var1 ÷ var2 - divRound(2)(var1 ÷ var2) = ?", vars)
+
+	p, err := Parse(vars, leaves(d))
+	require.NoError(t, err)
+	pvars, pformula, err := p.MathWith(MathOptions{Unicode: true})
+	require.NoError(t, err)
+	assert.Equal(t, vars, pvars)
+	assert.Equal(t, formula, pformula)
+}
+
+func TestMathWithNumbers(t *testing.T) {
+	d := NewFromFloatWithName("price", 1234.5).
+		Mul(NewFromFloatWithName("quantity", 3)).
+		Sub(NewFromFloatWithName("discount", 1234567.125)).
+		SetName("total")
+
+	tests := map[string]MathOptions{
+		"1234.5 * 3 - 1234567.125 = -1230863.625":        {},
+		"1234.50 * 3.00 - 1234567.13 = -1230863.63":      {Fixed: true, Places: 2},
+		"1,234.5 * 3 - 1,234,567.125 = -1,230,863.625":   {Separator: ","},
+		"1 235 * 3 - 1 234 567 = -1 230 864":             {Fixed: true, Separator: " "},
+		"1,234.50 × 3.00 - 1,234,567.13 = -1,230,863.63": {Fixed: true, Places: 2, Separator: ",", Unicode: true},
+		"1'230 * 0 - 1'234'570 = -1'230'860":             {Fixed: true, Places: -1, Separator: "'"},
+	}
+
+	for want, opts := range tests {
+		vars, formula, err := d.MathWith(opts)
+		require.NoError(t, err)
+		assert.Equal(t, want, formula)
+		assert.NotContains(t, vars, "1")
+	}
+}
+
+func TestMathWithUnknownParens(t *testing.T) {
+	_, _, err := NewFromInt(1).MathWith(MathOptions{Parens: 3})
+	assert.EqualError(t, err, "tomath: unknown parens mode 3")
+}
+
+func TestMathWithPlaceholders(t *testing.T) {
+	two := NewFromInt(2)
+	d := NewFromFloatWithName("var1", 1.5).
+		Add(two).
+		Mul(two).
+		Sub(NewFromInt(1000).Add(NewFromFloatWithName("t1", 3)))
+
+	tests := map[Placeholder][2]string{
+		PlaceholderUnknown:  {"(var1 + ?) * ? - (? + t1) = ?", "(1.5 + 2) * 2 - (1,000 + 3) = -996"},
+		PlaceholderGenerate: {"(var1 + t2) * t2 - (t3 + t1) = t4", "(1.5 + 2) * 2 - (1,000 + 3) = -996"},
+		PlaceholderValue:    {"(var1 + 2) * 2 - (1,000 + t1) = ?", "(1.5 + 2) * 2 - (1,000 + 3) = -996"},
+	}
+
+	for placeholder, want := range tests {
+		vars, formula, err := d.MathWith(MathOptions{Unnamed: placeholder, Separator: ","})
+		require.NoError(t, err)
+		assert.Equal(t, want[0], vars)
+		assert.Equal(t, want[1], formula)
+	}
+
+	vars, _, err := d.SetName("total").MathWith(MathOptions{Unnamed: PlaceholderGenerate})
+	require.NoError(t, err)
+	assert.Equal(t, "(var1 + t2) * t2 - (t3 + t1) = total", vars)
+}
+
+func TestMathWithPlaceholderError(t *testing.T) {
+	var1 := NewFromFloatWithName("var1", 1.5)
+
+	_, _, err := var1.Add(NewFromInt(2)).Mul(NewFromFloat(0.5)).MathWith(MathOptions{Unnamed: PlaceholderError})
+	var unnamed *UnnamedError
+	require.True(t, errors.As(err, &unnamed))
+	assert.Equal(t, []string{"2", "0.5"}, []string{unnamed.Values[0].String(), unnamed.Values[1].String()})
+	assert.True(t, unnamed.Result)
+	assert.EqualError(t, err, "tomath: unnamed values 2, 0.5 and result")
+
+	_, _, err = var1.Add(var1).MathWith(MathOptions{Unnamed: PlaceholderError})
+	assert.EqualError(t, err, "tomath: unnamed result")
+
+	vars, _, err := var1.Add(var1).SetName("var2").MathWith(MathOptions{Unnamed: PlaceholderError})
+	require.NoError(t, err)
+	assert.Equal(t, "var1 + var1 = var2", vars)
+
+	_, _, err = var1.MathWith(MathOptions{Unnamed: 4})
+	assert.EqualError(t, err, "tomath: unknown placeholder 4")
+}
+
+func TestMathWithExpand(t *testing.T) {
+	timesOneHundred := func(input Decimal) Decimal {
+		oneHundred := NewFromFloatWithName("OneHundred", 100)
+		return input.Mul(oneHundred).ResolveTo(input.GetName() + "Times" + oneHundred.GetName())
+	}
+
+	d := NewFromFloatWithName("var1", 1.1).
+		Add(NewFromFloatWithName("var2", 2)).
+		SetName("var5").
+		Resolve().
+		Add(timesOneHundred(NewFromFloatWithName("var6", 3))).
+		SetName("var7").
+		Resolve()
+
+	tests := []struct {
+		opts          MathOptions
+		vars, formula string
+	}{
+		{MathOptions{}, "var7 = var7", "303.1 = 303.1"},
+		{MathOptions{ExpandDepth: 1}, "var5 + var6TimesOneHundred = var7", "3.1 + 300 = 303.1"},
+		{MathOptions{Expand: []string{"var7"}}, "var5 + var6TimesOneHundred = var7", "3.1 + 300 = 303.1"},
+		{MathOptions{Expand: []string{"var7", "var6TimesOneHundred"}}, "var5 + (var6 * OneHundred) = var7", "3.1 + (3 * 100) = 303.1"},
+		{MathOptions{Expand: []string{"var6TimesOneHundred"}}, "var7 = var7", "303.1 = 303.1"},
+		{MathOptions{ExpandDepth: 2}, "(var1 + var2) + (var6 * OneHundred) = var7", "(1.1 + 2) + (3 * 100) = 303.1"},
+		{MathOptions{ExpandDepth: -1}, "(var1 + var2) + (var6 * OneHundred) = var7", "(1.1 + 2) + (3 * 100) = 303.1"},
+		{MathOptions{ExpandDepth: 1, Expand: []string{"var5"}}, "(var1 + var2) + var6TimesOneHundred = var7", "(1.1 + 2) + 300 = 303.1"},
+	}
+
+	for _, test := range tests {
+		vars, formula, err := d.MathWith(test.opts)
+		require.NoError(t, err)
+		assert.Equal(t, test.vars, vars)
+		assert.Equal(t, test.formula, formula)
+	}
+
+	vars, formula := d.Math()
+	assert.Equal(t, "var7 = var7", vars)
+	assert.Equal(t, "303.1 = 303.1", formula)
+}
+
+func TestMathWithExpandScope(t *testing.T) {
+	s := NewScope("fees")
+	fee := s.SetName(s.NewFromInt("base", 2).Mul(s.NewFromInt("rate", 3)), "total")
+	d := NewFromIntWithName("price", 10).Add(fee).SetName("subtotal").Resolve().Sub(NewFromIntWithName("discount", 1))
+
+	vars, _, err := d.MathWith(MathOptions{ExpandDepth: 1, Collapse: []Scope{s}})
+	require.NoError(t, err)
+	assert.Equal(t, "(price + fees.total) - discount = ?", vars)
+
+	vars, _, err = d.MathWith(MathOptions{ExpandDepth: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "(price + fees.base * fees.rate) - discount = ?", vars)
+}
@@ -0,0 +1,737 @@
+package tomath
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// ParseError is returned by Parse when the expression does not follow the
+// grammar emitted by Math().
+type ParseError struct {
+	// Offset is the byte offset in the expression at which parsing failed.
+	Offset int
+	Msg    string
+}
+
+func (e *ParseError) Error() string {
+	return "tomath: " + e.Msg + " at offset " + strconv.Itoa(e.Offset)
+}
+
+// Parse builds a Decimal from an expression in the grammar emitted by Math().
+// Names are looked up in bindings and numbers are used as unnamed values, so
+// both the names and the values output of Math() can be parsed.
+//
+// The expression may be followed by an equals sign and the name of the result.
+// A "?" or a number on the right side of the equals sign leaves the result
+// unnamed. Names quoted with backticks are read verbatim, see ValidateName().
+//
+// Example:
+//
+//     d, err := Parse("round(1)(var1) + var2 = var3", map[string]Decimal{
+//         "var1": NewFromFloat(1.12),
+//         "var2": NewFromFloat(2),
+//     })
+//     d.String() // output: "3.1"
+//
+// A result written as a percentage or in basis points, ex: "= 25%", is written
+// the same way by the Decimal returned.
+//
+// A quoRem() yields the quotient and a quoRem() marked "rem" the remainder,
+// ex: "quoRem(2, rem)(var1 / var2)".
+//
+// An operation failing on its operands, ex: "1 / 0" or "sqrt(-1)", returns a
+// *ParseError at the offset of its operator or function name.
+//
+// Divisions are rendered without their precision and are read back with
+// decimal.DivisionPrecision, as are square roots, exponentials and logarithms
+// written without theirs, ex: "sqrt(var1)" rather than "sqrt(4)(var1)".
+// Operations rounded by a Context are read back with it, ex:
+// "div[prec=4](var1, var2)".
+func Parse(expr string, bindings map[string]Decimal) (Decimal, error) {
+	p := &parser{lexer: lexer{src: expr}, bindings: bindings, offsets: map[*Expr]int{}}
+	p.next()
+	e, err := p.expr()
+	if err != nil {
+		return Decimal{}, err
+	}
+
+	name, n, err := p.result()
+	if err != nil {
+		return Decimal{}, err
+	}
+
+	d, err := p.eval(e)
+	if err != nil {
+		return Decimal{}, err
+	}
+
+	if n != notationPlain {
+		d = d.notated(n)
+	}
+	if name != "" {
+		d = d.SetName(name)
+	}
+
+	return d, nil
+}
+
+type tokKind uint8
+
+const (
+	tokEOF tokKind = iota
+	tokWord
+	tokOp
+	tokQuoted
+	tokUnterminated
+	tokContext
+)
+
+type token struct {
+	kind tokKind
+	text string
+	pos  int
+}
+
+// lexer splits an expression into words (names, numbers and function names),
+// quoted names, contexts and single character operators.
+type lexer struct {
+	src string
+	pos int
+}
+
+const operators = "()+-*/^%,="
+
+// unicodeOperators maps the operators spelled by MathOptions.Unicode to their
+// ASCII equivalent.
+var unicodeOperators = map[string]string{"×": "*", "÷": "/"}
+
+func (l *lexer) next() token {
+	for l.pos < len(l.src) && isSpace(l.src[l.pos]) {
+		l.pos++
+	}
+
+	if l.pos == len(l.src) {
+		return token{kind: tokEOF, pos: l.pos}
+	}
+
+	start := l.pos
+	if op, n := l.operator(); n > 0 {
+		l.pos += n
+		return token{kind: tokOp, text: op, pos: start}
+	}
+
+	if strings.HasPrefix(l.src[l.pos:], quote) {
+		return l.quoted()
+	}
+
+	if strings.HasPrefix(l.src[l.pos:], contextStart) {
+		if end := strings.Index(l.src[l.pos:], contextClose); end >= 0 {
+			l.pos += end + len(contextClose)
+			return token{kind: tokContext, text: l.src[start+len(contextOpen) : l.pos-len(contextClose)], pos: start}
+		}
+	}
+
+	for l.pos < len(l.src) && !isSpace(l.src[l.pos]) && !strings.HasPrefix(l.src[l.pos:], quote) {
+		if n := rateNameLen(l.src[l.pos:]); l.pos == start && n > 0 {
+			l.pos += n
+			break
+		}
+		if _, n := l.operator(); n > 0 {
+			break
+		}
+		if l.pos > start && strings.HasPrefix(l.src[l.pos:], contextStart) {
+			break
+		}
+		l.pos++
+	}
+
+	return token{kind: tokWord, text: l.src[start:l.pos], pos: start}
+}
+
+// quoted reads a name quoted with backticks in which doubled backticks stand
+// for a single one.
+func (l *lexer) quoted() token {
+	start := l.pos
+	var b strings.Builder
+	for l.pos++; l.pos < len(l.src); l.pos++ {
+		if strings.HasPrefix(l.src[l.pos:], quote) {
+			if !strings.HasPrefix(l.src[l.pos+1:], quote) {
+				l.pos++
+				return token{kind: tokQuoted, text: b.String(), pos: start}
+			}
+			l.pos++
+		}
+		b.WriteByte(l.src[l.pos])
+	}
+
+	return token{kind: tokUnterminated, text: l.src[start:], pos: start}
+}
+
+// operator returns the operator at the current position and its length in
+// bytes, or a length of 0 if there is none.
+func (l *lexer) operator() (string, int) {
+	if strings.IndexByte(operators, l.src[l.pos]) >= 0 {
+		return l.src[l.pos : l.pos+1], 1
+	}
+	for u, op := range unicodeOperators {
+		if strings.HasPrefix(l.src[l.pos:], u) {
+			return op, len(u)
+		}
+	}
+	return "", 0
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// parser is a recursive descent parser producing an unevaluated expression
+// tree whose leaves hold the bound values.
+type parser struct {
+	lexer
+	tok      token
+	bindings map[string]Decimal
+	// offsets holds the offset of the operator or function name of the
+	// operations.
+	offsets map[*Expr]int
+}
+
+// op returns the operation e whose operator or function name is at offset pos.
+func (p *parser) op(e *Expr, pos int) *Expr {
+	p.offsets[e] = pos
+	return e
+}
+
+// eval evaluates the parsed expression. The panic of an operation failing on
+// its operands is returned as a *ParseError.
+func (p *parser) eval(e *Expr) (d Decimal, err error) {
+	if e.op == OpLeaf {
+		return Decimal{name: e.name, decimal: e.value, expr: e}, nil
+	}
+
+	operands := make([]Decimal, len(e.operands))
+	for i, o := range e.operands {
+		if operands[i], err = p.eval(o); err != nil {
+			return Decimal{}, err
+		}
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = &ParseError{Offset: p.offsets[e], Msg: strings.TrimPrefix(panicMessage(r), "tomath: ")}
+		}
+	}()
+
+	return e.finish(apply(e.op, e.params, operands)), nil
+}
+
+// panicMessage returns the message of the value passed to panic.
+func panicMessage(r interface{}) string {
+	switch v := r.(type) {
+	case error:
+		return v.Error()
+	case string:
+		return v
+	}
+	return "invalid operation"
+}
+
+func (p *parser) next() {
+	p.tok = p.lexer.next()
+}
+
+func (p *parser) errorf(msg string) error {
+	return &ParseError{Offset: p.tok.pos, Msg: msg}
+}
+
+func (p *parser) is(op string) bool {
+	return p.tok.kind == tokOp && p.tok.text == op
+}
+
+func (p *parser) expect(op string) error {
+	if !p.is(op) {
+		return p.errorf("expected " + strconv.Quote(op))
+	}
+	p.next()
+	return nil
+}
+
+// result parses the optional equals sign followed by the name of the result
+// ending the expression. It returns an empty name for a "?" or a number, along
+// with the notation of the number, ex: "= 25%".
+func (p *parser) result() (string, notation, error) {
+	if p.tok.kind == tokEOF {
+		return "", notationPlain, nil
+	}
+	if !p.is("=") {
+		return "", notationPlain, p.errorf("unexpected " + strconv.Quote(p.tok.text))
+	}
+	p.next()
+
+	name, n := "", notationPlain
+	switch {
+	case p.is("-"):
+		p.next()
+		word := p.tok
+		if word.kind != tokWord {
+			return "", notationPlain, p.errorf("expected a number")
+		}
+		p.next()
+		v, ok := p.number(word)
+		if !ok {
+			return "", notationPlain, &ParseError{Offset: word.pos, Msg: "expected a number"}
+		}
+		n = v.notation
+	case p.tok.kind == tokQuoted:
+		name = p.tok.text
+		p.next()
+	case p.tok.kind == tokUnterminated:
+		return "", notationPlain, p.errorf("unterminated quoted name")
+	case p.tok.kind == tokWord:
+		word := p.tok
+		p.next()
+		if v, ok := p.number(word); ok {
+			n = v.notation
+		} else if word.text != unknown {
+			name = word.text
+		}
+	default:
+		return "", notationPlain, p.errorf("expected a name")
+	}
+
+	if p.tok.kind != tokEOF {
+		return "", notationPlain, p.errorf("unexpected " + strconv.Quote(p.tok.text))
+	}
+
+	return name, n, nil
+}
+
+// expr parses additions and subtractions.
+func (p *parser) expr() (*Expr, error) {
+	e, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.is("+") || p.is("-") {
+		op, pos := OpAdd, p.tok.pos
+		if p.is("-") {
+			op = OpSub
+		}
+		p.next()
+
+		e2, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		e = p.op(&Expr{op: op, operands: []*Expr{e, e2}}, pos)
+	}
+
+	return e, nil
+}
+
+// term parses multiplications, divisions and modulos.
+func (p *parser) term() (*Expr, error) {
+	e, err := p.power()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.is("*") || p.is("/") || p.is("%") {
+		op, pos := OpMul, p.tok.pos
+		if p.is("/") {
+			op = OpDiv
+		} else if p.is("%") {
+			op = OpMod
+		}
+		p.next()
+
+		e2, err := p.power()
+		if err != nil {
+			return nil, err
+		}
+		e = p.op(&Expr{op: op, operands: []*Expr{e, e2}}, pos)
+	}
+
+	return e, nil
+}
+
+// power parses right associative exponentiations.
+func (p *parser) power() (*Expr, error) {
+	e, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.is("^") {
+		pos := p.tok.pos
+		p.next()
+		e2, err := p.power()
+		if err != nil {
+			return nil, err
+		}
+		e = p.op(&Expr{op: OpPow, operands: []*Expr{e, e2}}, pos)
+	}
+
+	return e, nil
+}
+
+// primary parses numbers, names, calls and parenthesized expressions.
+func (p *parser) primary() (*Expr, error) {
+	switch {
+	case p.is("("):
+		p.next()
+		e, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		return e, p.expect(")")
+	case p.is("-"):
+		p.next()
+		if p.tok.kind != tokWord {
+			return nil, p.errorf("expected a number")
+		}
+		word := p.tok
+		p.next()
+		e, ok := p.number(word)
+		if !ok {
+			return nil, &ParseError{Offset: word.pos, Msg: "invalid number " + strconv.Quote("-"+word.text)}
+		}
+		e.value = e.value.Neg()
+		return e, nil
+	case p.tok.kind == tokEOF:
+		return nil, p.errorf("unexpected end of expression")
+	case p.tok.kind == tokUnterminated:
+		return nil, p.errorf("unterminated quoted name")
+	case p.tok.kind == tokQuoted:
+		word := p.tok
+		p.next()
+		return p.bind(word)
+	case p.tok.kind != tokWord:
+		return nil, p.errorf("unexpected " + strconv.Quote(p.tok.text))
+	}
+
+	word := p.tok
+	p.next()
+
+	if p.tok.kind == tokContext {
+		params, err := p.context()
+		if err != nil {
+			return nil, err
+		}
+		if !p.is("(") {
+			return nil, p.errorf("expected \"(\"")
+		}
+		return p.call(word, params)
+	}
+
+	if p.is("(") {
+		return p.call(word, nil)
+	}
+
+	if e, ok := p.number(word); ok {
+		return e, nil
+	}
+
+	return p.bind(word)
+}
+
+// number returns the value of word if it is a number. A number immediately
+// followed by "%" is a percentage, the modulo being surrounded by spaces in
+// Math().
+func (p *parser) number(word token) (*Expr, bool) {
+	if v, n, ok := parseNotation(word.text); ok {
+		return &Expr{value: v, notation: n}, true
+	}
+
+	v, err := decimal.NewFromString(word.text)
+	if err != nil {
+		return nil, false
+	}
+
+	if p.is(percentSign) && p.tok.pos == word.pos+len(word.text) {
+		p.next()
+		return &Expr{value: v.Shift(-notations[notationPercent].shift), notation: notationPercent}, true
+	}
+
+	return &Expr{value: v}, true
+}
+
+// bind looks up the value of the name word.
+func (p *parser) bind(word token) (*Expr, error) {
+	b, ok := p.bindings[word.text]
+	if !ok {
+		return nil, &ParseError{Offset: word.pos, Msg: "unbound name " + strconv.Quote(word.text)}
+	}
+
+	return &Expr{name: word.text, value: b.decimal, notation: b.node().notation}, nil
+}
+
+// call parses the arguments of the function fn whose opening parenthesis is the
+// current token. context holds the parameters of the Context of the call, if
+// any.
+func (p *parser) call(fn token, context []int32) (*Expr, error) {
+	op, ok := lookupFunc(fn.text)
+	if context != nil {
+		op, ok = lookupOp(fn.text)
+		if ok && !takesContext(op) {
+			return nil, &ParseError{Offset: fn.pos, Msg: fn.text + " does not take a context"}
+		}
+	}
+	if !ok {
+		return nil, &ParseError{Offset: fn.pos, Msg: "unknown function " + strconv.Quote(fn.text)}
+	}
+	p.next()
+
+	switch op {
+	case OpShift, OpRound, OpRoundBank, OpRoundCash, OpTruncate, OpQuoRem, OpDivRound:
+		n, err := p.param()
+		if err != nil {
+			return nil, err
+		}
+		params := []int32{n}
+		if op == OpQuoRem {
+			part, err := p.quoRemPart()
+			if err != nil {
+				return nil, err
+			}
+			params = append(params, part)
+		}
+		if op == OpRound && p.is(",") {
+			p.next()
+			mode, err := p.roundMode()
+			if err != nil {
+				return nil, err
+			}
+			params = append(params, int32(mode))
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		if op == OpRoundCash && !validCashInterval(n) {
+			return nil, p.errorf("invalid roundCash interval " + strconv.Itoa(int(n)))
+		}
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+
+		pos := p.tok.pos
+		e, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+
+		if op == OpQuoRem || op == OpDivRound {
+			if e.op != OpDiv || len(e.params) != 0 {
+				return nil, &ParseError{Offset: pos, Msg: fn.text + " expects a division"}
+			}
+			return p.op(&Expr{op: op, params: params, operands: e.operands}, fn.pos), nil
+		}
+
+		return p.op(&Expr{op: op, params: params, operands: []*Expr{e}}, fn.pos), nil
+	case OpRoundToIncrement:
+		mode, err := p.roundMode()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+
+		e, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+		increment, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+
+		return p.op(&Expr{op: op, params: []int32{int32(mode)}, operands: []*Expr{e, increment}}, fn.pos), p.expect(")")
+	case OpMin, OpMax, OpSum, OpAvg:
+		var operands []*Expr
+		for {
+			e, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			operands = append(operands, e)
+
+			if !p.is(",") {
+				break
+			}
+			p.next()
+		}
+
+		return p.op(&Expr{op: op, operands: operands}, fn.pos), p.expect(")")
+	case OpLog, OpAtan2, OpDiv:
+		precision := p.precision(op)
+		e, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+		e2, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+
+		params := context
+		if op == OpLog {
+			params = []int32{precision}
+		}
+
+		return p.op(&Expr{op: op, params: params, operands: []*Expr{e, e2}}, fn.pos), p.expect(")")
+	default:
+		precision := p.precision(op)
+		e, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+
+		params := context
+		switch op {
+		case OpSqrt, OpExp, OpLn, OpLog10:
+			params = []int32{precision}
+		}
+
+		return p.op(&Expr{op: op, params: params, operands: []*Expr{e}}, fn.pos), p.expect(")")
+	}
+}
+
+// precision parses the precision of a square root, an exponential or a
+// logarithm, ex: the "16" of "sqrt(16)(var1)", whose first parenthesis has been
+// read. Without one, as in "sqrt(var1)", it returns decimal.DivisionPrecision
+// and leaves the arguments to be read.
+func (p *parser) precision(op Op) int32 {
+	switch op {
+	case OpSqrt, OpExp, OpLn, OpLog10, OpLog:
+	default:
+		return 0
+	}
+
+	lexer, tok := p.lexer, p.tok
+	if n, err := p.param(); err == nil && p.is(")") {
+		p.next()
+		if p.is("(") {
+			p.next()
+			return n
+		}
+	}
+	p.lexer, p.tok = lexer, tok
+
+	return int32(decimal.DivisionPrecision)
+}
+
+// context parses the context of a call, ex: "[prec=16, round=halfEven]".
+func (p *parser) context() ([]int32, error) {
+	var c Context
+	for _, field := range strings.Split(p.tok.text, ",") {
+		kv := strings.SplitN(strings.TrimSpace(field), "=", 2)
+		if len(kv) != 2 {
+			return nil, p.errorf("invalid context " + strconv.Quote(p.tok.text))
+		}
+
+		switch kv[0] {
+		case "prec", "digits":
+			n, err := strconv.ParseInt(kv[1], 10, 32)
+			if err != nil {
+				return nil, p.errorf("invalid " + kv[0] + " " + strconv.Quote(kv[1]))
+			}
+			if kv[0] == "prec" {
+				c.Precision = int32(n)
+			} else {
+				c.MaxDigits = int32(n)
+			}
+		case "round":
+			m, ok := lookupRoundMode(kv[1])
+			if !ok {
+				return nil, p.errorf("unknown rounding mode " + strconv.Quote(kv[1]))
+			}
+			c.Rounding = m
+		default:
+			return nil, p.errorf("unknown context setting " + strconv.Quote(kv[0]))
+		}
+	}
+	p.next()
+
+	return c.params(), nil
+}
+
+// quoRemPart parses the optional "rem" parameter of a quoRem() and returns 1
+// for the remainder or 0 for the quotient.
+func (p *parser) quoRemPart() (int32, error) {
+	if !p.is(",") {
+		return 0, nil
+	}
+	p.next()
+	if p.tok.kind != tokWord || p.tok.text != rem {
+		return 0, p.errorf("expected " + strconv.Quote(rem))
+	}
+	p.next()
+
+	return 1, nil
+}
+
+// roundMode parses the rounding mode parameter of a call.
+func (p *parser) roundMode() (RoundMode, error) {
+	mode, ok := lookupRoundMode(p.tok.text)
+	if p.tok.kind != tokWord || !ok {
+		return 0, p.errorf("unknown rounding mode " + strconv.Quote(p.tok.text))
+	}
+	p.next()
+
+	return mode, nil
+}
+
+// param parses the integer parameter of a call.
+func (p *parser) param() (int32, error) {
+	text := ""
+	if p.is("-") {
+		text = "-"
+		p.next()
+	}
+	if p.tok.kind != tokWord {
+		return 0, p.errorf("expected an integer")
+	}
+	text += p.tok.text
+
+	n, err := strconv.ParseInt(text, 10, 32)
+	if err != nil {
+		return 0, p.errorf("invalid integer " + strconv.Quote(text))
+	}
+	p.next()
+
+	return int32(n), nil
+}
+
+// lookupFunc returns the operation rendered as a call to name.
+func lookupFunc(name string) (Op, bool) {
+	op, ok := lookupOp(name)
+	switch op {
+	case OpLeaf, OpAdd, OpSub, OpMul, OpDiv, OpMod, OpPow:
+		return 0, false
+	}
+	return op, ok
+}
+
+func validCashInterval(n int32) bool {
+	switch n {
+	case 5, 10, 25, 50, 100:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,236 @@
+package tomath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// leaves returns the named leaves of d as bindings for Parse.
+func leaves(d Decimal) map[string]Decimal {
+	bindings := map[string]Decimal{}
+	var walk func(e *Expr)
+	walk = func(e *Expr) {
+		if e.IsLeaf() && e.Name() != "" {
+			bindings[e.Name()] = NewFromDecimalWithName(e.Name(), e.Value())
+		}
+		for _, o := range e.Operands() {
+			walk(o)
+		}
+	}
+	walk(d.Expr())
+	return bindings
+}
+
+func TestParseRoundTrip(t *testing.T) {
+	var1 := NewFromFloatWithName("var1", 4.333)
+	var2 := NewFromFloatWithName("var2", 2.7)
+	var3 := NewFromFloatWithName("var3", -1)
+	q, r := var1.QuoRem(var2, 3)
+
+	tests := map[string]Decimal{
+		"leaf":      var1,
+		"alias":     var1.SetName("var4"),
+		"abs":       var3.Abs(),
+		"add":       var1.Add(var2),
+		"sub":       var1.Sub(var2),
+		"neg":       var1.Neg(),
+		"mul":       var1.Add(var2).Mul(var3.Sub(var1)),
+		"shift":     var1.Shift(-2),
+		"div":       var1.Div(var2.Add(var3)),
+		"quotient":  q,
+		"remainder": r,
+		"quoRem":    q.Add(var1),
+		"nestedRem": r.Add(var1).Mul(var2),
+		"renamed":   r.SetName("leftover"),
+		"divRound":  var1.Add(var3).DivRound(var2, 3),
+		"mod":       var1.Mod(var2),
+		"pow":       var3.Pow(var2.Round(0)),
+		"round":     var1.Round(-1).SetName("var5"),
+		"roundBank": var1.RoundBank(2),
+		"roundCash": var1.RoundCash(25),
+		"floor":     var1.Floor(),
+		"ceil":      var1.Ceil(),
+		"truncate":  var1.Truncate(1),
+		"min":       Min(var1, var2.Add(var3), var3),
+		"max":       Max(var1, var2),
+		"sum":       Sum(var1, var2, var3.Mul(var2)),
+		"avg":       Avg(var1, var2),
+		"atan":      var1.Atan(),
+		"sin":       var1.Sin(),
+		"cos":       var1.Cos(),
+		"tan":       var1.Tan(),
+		"sqrt":      var1.Sqrt(16),
+		"sqrt4":     var2.Sqrt(4).Add(var1.Exp(2)),
+		"log4":      var1.Log(var2, 4).Mul(var2.Ln(3).Sub(var1.Log10(0))),
+		"exp":       var2.Exp(16),
+		"ln":        var1.Ln(16),
+		"log10":     var1.Log10(16),
+		"log":       var1.Log(var2.Add(var3), 16),
+		"asin":      var3.Asin(),
+		"acos":      var3.Neg().Acos(),
+		"atan2":     var3.Atan2(var1.Sub(var2)),
+		"sinh":      var1.Sinh(),
+		"cosh":      var2.Cosh(),
+		"tanh":      var3.Tanh(),
+		"asinh":     var3.Asinh(),
+		"acosh":     var1.Acosh(),
+		"atanh":     var3.Div(var2).Atanh(),
+		"radians":   var1.Radians(),
+		"degrees":   var2.Degrees(),
+		"roundWith": var1.RoundWith(2, Round05Up),
+		"increment": var1.RoundToIncrement(var2, RoundHalfOdd),
+		"context":   var1.WithContext(Context{Precision: 4, Rounding: RoundFloor, MaxDigits: 3}).Div(var2).Sin(),
+		"complex": NewFromFloatWithName("var1", 1.1).
+			Round(1).
+			Add(NewFromFloatWithName("var2", 1)).
+			Add(NewFromFloatWithName("var2", 1)).
+			Div(NewFromFloatWithName("var3", 2)).
+			Mul(NewFromFloatWithName("OneHundred", 100).Add(NewFromFloatWithName("var4", 3))).
+			SetName("var5"),
+	}
+
+	for name, d := range tests {
+		t.Run(name, func(t *testing.T) {
+			vars, formula := d.Math()
+
+			p, err := Parse(vars, leaves(d))
+			require.NoError(t, err)
+			pvars, pformula := p.Math()
+			assert.Equal(t, vars, pvars)
+			assert.Equal(t, formula, pformula)
+			assert.Equal(t, d.GetName(), p.GetName())
+		})
+	}
+}
+
+func TestParseQuoRem(t *testing.T) {
+	q, r := NewFromIntWithName("a", 7).QuoRem(NewFromIntWithName("b", 2), 0)
+	bindings := map[string]Decimal{"a": NewFromInt(7), "b": NewFromInt(2), "c": NewFromInt(10)}
+
+	tests := []struct {
+		d    Decimal
+		vars string
+		want string
+	}{
+		{q, "quoRem(0)(a / b) = abQuotient", "3"},
+		{r, "quoRem(0, rem)(a / b) = abRemainder", "1"},
+		{r.Add(NewFromIntWithName("c", 10)), "quoRem(0, rem)(a / b) + c = ?", "11"},
+		{r.SetName("leftover"), "quoRem(0, rem)(a / b) = leftover", "1"},
+		{q.SetName("abRemainder"), "quoRem(0)(a / b) = abRemainder", "3"},
+	}
+
+	for _, tt := range tests {
+		vars, _ := tt.d.Math()
+		assert.Equal(t, tt.vars, vars)
+
+		p, err := Parse(vars, bindings)
+		require.NoError(t, err, vars)
+		assert.Equal(t, tt.want, p.String(), vars)
+	}
+}
+
+func TestParseFormula(t *testing.T) {
+	d := NewFromFloatWithName("var1", 1.1).
+		Round(1).
+		Add(NewFromFloatWithName("var2", -1)).
+		Div(NewFromFloatWithName("var3", 2))
+	_, formula := d.Math()
+
+	p, err := Parse(formula, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "", p.GetName())
+	_, pformula := p.Math()
+	assert.Equal(t, formula, pformula)
+}
+
+func TestParseBindings(t *testing.T) {
+	d, err := Parse("round(1)(var1) + var2 = var3", map[string]Decimal{
+		"var1": NewFromFloat(1.12),
+		"var2": NewFromFloat(2),
+	})
+	require.NoError(t, err)
+
+	vars, formula := d.Math()
+	assert.Equal(t, "round(1)(var1) + var2 = var3", vars)
+	assert.Equal(t, "round(1)(1.12) + 2 = 3.1", formula)
+}
+
+func TestParsePrecedence(t *testing.T) {
+	tests := map[string]string{
+		"1 + 2 * 3":         "7",
+		"(1 + 2) * 3":       "9",
+		"2^3^2":             "512",
+		"10 - 4 - 3":        "3",
+		"7 % 4 * 2":         "6",
+		"-2 * 3":            "-6",
+		"round(-1)(14) - 1": "9",
+		"sqrt(4)(2)":        "1.4142",
+		"sqrt(2)":           "1.414213562373095",
+		"sqrt(4)":           "2",
+		"log(2)(100, 10)":   "2",
+		"ln(2)(1 + 1) * 2":  "1.38",
+	}
+
+	for expr, want := range tests {
+		d, err := Parse(expr, nil)
+		require.NoError(t, err, expr)
+		assert.Equal(t, want, d.String(), expr)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := map[string]string{
+		"var1 + var2":                        `tomath: unbound name "var2" at offset 7`,
+		"var1 +":                             `tomath: unexpected end of expression at offset 6`,
+		"(var1":                              `tomath: expected ")" at offset 5`,
+		"var1 var1":                          `tomath: unexpected "var1" at offset 5`,
+		"foo(var1)":                          `tomath: unknown function "foo" at offset 0`,
+		"add(var1)":                          `tomath: unknown function "add" at offset 0`,
+		"round(x)(var1)":                     `tomath: invalid integer "x" at offset 6`,
+		"roundCash(3)(var1)":                 `tomath: invalid roundCash interval 3 at offset 12`,
+		"quoRem(3)(var1)":                    `tomath: quoRem expects a division at offset 10`,
+		"quoRem(3, foo)(var1 / var1)":        `tomath: expected "rem" at offset 10`,
+		"- var1":                             `tomath: invalid number "-var1" at offset 2`,
+		"div[prec=x](var1, var1)":            `tomath: invalid prec "x" at offset 3`,
+		"div[prec=2, round=up2](var1, var1)": `tomath: unknown rounding mode "up2" at offset 3`,
+		"div[prec=2, foo=1](var1, var1)":     `tomath: unknown context setting "foo" at offset 3`,
+		"round[prec=2](var1)":                `tomath: round does not take a context at offset 0`,
+		"sin[prec=2] var1":                   `tomath: expected "(" at offset 12`,
+		"round(2, foo)(var1)":                `tomath: unknown rounding mode "foo" at offset 9`,
+		"roundToIncrement(up)(var1)":         `tomath: expected "," at offset 25`,
+	}
+
+	for expr, want := range tests {
+		_, err := Parse(expr, map[string]Decimal{"var1": NewFromInt(1)})
+		assert.EqualError(t, err, want, expr)
+	}
+}
+
+func TestParseEvaluationErrors(t *testing.T) {
+	tests := map[string]string{
+		"1 / 0":                          `tomath: decimal division by 0 at offset 2`,
+		"1 % 0":                          `tomath: decimal division by 0 at offset 2`,
+		"0 ^ -1":                         `tomath: decimal division by 0 at offset 2`,
+		"2 * (1 + 1 / 0)":                `tomath: decimal division by 0 at offset 11`,
+		"quoRem(2)(1 / 0)":               `tomath: decimal division by 0 at offset 0`,
+		"div[prec=2](1, 0)":              `tomath: decimal division by 0 at offset 0`,
+		"sqrt(-1)":                       `tomath: square root of negative number at offset 0`,
+		"ln(0)":                          `tomath: logarithm of non-positive number at offset 0`,
+		"1 + log(2, 1)":                  `tomath: invalid logarithm base 1 at offset 4`,
+		"asin(2)":                        `tomath: asin argument out of range at offset 0`,
+		"acosh(0)":                       `tomath: acosh argument out of range at offset 0`,
+		"atanh(1)":                       `tomath: atanh argument out of range at offset 0`,
+		"roundToIncrement(halfUp)(1, 0)": `tomath: rounding increment must be positive at offset 0`,
+	}
+
+	for expr, want := range tests {
+		var d Decimal
+		var err error
+		require.NotPanics(t, func() { d, err = Parse(expr, nil) }, expr)
+		assert.EqualError(t, err, want, expr)
+		assert.IsType(t, &ParseError{}, err, expr)
+		assert.Equal(t, Decimal{}, d, expr)
+	}
+}
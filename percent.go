@@ -0,0 +1,134 @@
+package tomath
+
+import (
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	percentSign = "%"
+	basisPoint  = "bp"
+)
+
+// notation selects how a value is written in formulas.
+type notation uint8
+
+const (
+	notationPlain notation = iota
+	notationPercent
+	notationBasisPoints
+)
+
+var notations = [...]struct {
+	name   string
+	shift  int32
+	suffix string
+}{
+	notationPlain:       {"", 0, ""},
+	notationPercent:     {"percent", 2, percentSign},
+	notationBasisPoints: {"basisPoints", 4, basisPoint},
+}
+
+// lookupNotation returns the notation named name.
+func lookupNotation(name string) (notation, bool) {
+	for n, v := range notations {
+		if v.name == name {
+			return notation(n), true
+		}
+	}
+	return 0, false
+}
+
+// Percent returns d percent, ex: 8.25 percent is 0.0825. It is written as a
+// percentage in formulas, ex: "price * 8.25%" for an unnamed rate. The value
+// is exact.
+//
+// Example:
+//
+//     tax := NewFromFloatWithName("price", 100).PercentOf(Percent(NewFromFloat(8.25))).SetName("tax")
+//     vars, formula := tax.Math()
+//     // vars:    "price * 8.25% = tax"
+//     // formula: "100 * 8.25% = 8.25"
+//
+// A computed d is shifted, ex: "shift(-2)(var1 + var2)".
+func Percent(d Decimal) Decimal {
+	return d.withNotation(notationPercent)
+}
+
+// BasisPoints returns d basis points, ex: 25 basis points are 0.0025. It is
+// written in basis points in formulas, ex: "rate + 25bp". The value is exact.
+func BasisPoints(d Decimal) Decimal {
+	return d.withNotation(notationBasisPoints)
+}
+
+// PercentOf returns the percentage p of d, ex: "price * 8.25%". p is a
+// fraction such as the ones returned by Percent() and BasisPoints().
+func (d Decimal) PercentOf(p Decimal) Decimal {
+	return d.Mul(p)
+}
+
+// IncreaseByPercent returns d increased by the percentage p of d, ex:
+// "price + price * 8.25%".
+func (d Decimal) IncreaseByPercent(p Decimal) Decimal {
+	return d.Add(d.Mul(p))
+}
+
+// DecreaseByPercent returns d decreased by the percentage p of d, ex:
+// "price - price * 10%".
+func (d Decimal) DecreaseByPercent(p Decimal) Decimal {
+	return d.Sub(d.Mul(p))
+}
+
+// PercentChange returns the relative change from a to b, written as a
+// percentage in formulas, ex: "(new - old) / old = 10%". It panics if a is 0.
+func PercentChange(a, b Decimal) Decimal {
+	return b.Sub(a).Div(a).notated(notationPercent)
+}
+
+// withNotation returns d divided by the base of n, written in n. A leaf is
+// divided in place, any other decimal is shifted.
+func (d Decimal) withNotation(n notation) Decimal {
+	if e := d.node(); e.op == OpLeaf && e.resolved == nil {
+		leaf := *e
+		leaf.value = e.value.Shift(-notations[n].shift)
+		leaf.notation = n
+		d.decimal, d.expr = leaf.value, &leaf
+		return d
+	}
+	return d.Shift(-notations[n].shift).notated(n)
+}
+
+// notated returns d written in n.
+func (d Decimal) notated(n notation) Decimal {
+	e := *d.node()
+	e.notation = n
+	d.expr = &e
+	return d
+}
+
+// number returns the value of the node in its notation and the suffix of the
+// notation, ex: 8.25 and "%".
+func (e *Expr) number() (decimal.Decimal, string) {
+	n := notations[e.notation]
+	return e.value.Shift(n.shift), n.suffix
+}
+
+// literal returns the value of the node in its notation, ex: "8.25%".
+func (e *Expr) literal() string {
+	v, suffix := e.number()
+	return v.String() + suffix
+}
+
+// parseNotation returns the value of a number written in a notation, ex:
+// "25bp". The percentages are read by the parser since "%" is an operator.
+func parseNotation(s string) (decimal.Decimal, notation, bool) {
+	if !strings.HasSuffix(s, basisPoint) {
+		return decimal.Decimal{}, 0, false
+	}
+	v, err := decimal.NewFromString(strings.TrimSuffix(s, basisPoint))
+	if err != nil {
+		return decimal.Decimal{}, 0, false
+	}
+	return v.Shift(-notations[notationBasisPoints].shift), notationBasisPoints, true
+}
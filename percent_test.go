@@ -0,0 +1,138 @@
+package tomath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPercent(t *testing.T) {
+	assert.Equal(t, "0.0825", Percent(NewFromFloat(8.25)).String())
+	assert.Equal(t, "0.0025", BasisPoints(NewFromInt(25)).String())
+	assert.Equal(t, "-0.5", Percent(NewFromInt(-50)).String())
+
+	rate := Percent(NewFromFloatWithName("rate", 8.25))
+	assert.Equal(t, "rate", rate.GetName())
+	vars, formula := rate.Math()
+	assert.Equal(t, "rate = rate", vars)
+	assert.Equal(t, "8.25% = 8.25%", formula)
+
+	vars, formula = Percent(NewFromFloatWithName("a", 8).Add(NewFromFloatWithName("b", 0.25))).Math()
+	assert.Equal(t, "shift(-2)(a + b) = ?", vars)
+	assert.Equal(t, "shift(-2)(8 + 0.25) = 8.25%", formula)
+}
+
+func TestPercentMath(t *testing.T) {
+	price := NewFromFloatWithName("price", 19.99)
+
+	tests := []struct {
+		d       Decimal
+		vars    string
+		formula string
+	}{
+		{price.PercentOf(Percent(NewFromFloat(8.25))), "price * 8.25% = ?", "19.99 * 8.25% = 1.649175"},
+		{price.PercentOf(Percent(NewFromFloatWithName("rate", 8.25))), "price * rate = ?", "19.99 * 8.25% = 1.649175"},
+		{price.IncreaseByPercent(Percent(NewFromFloat(8.25))), "price + price * 8.25% = ?", "19.99 + 19.99 * 8.25% = 21.639175"},
+		{price.DecreaseByPercent(Percent(NewFromInt(10))), "price - price * 10% = ?", "19.99 - 19.99 * 10% = 17.991"},
+		{price.DecreaseByPercent(BasisPoints(NewFromInt(25))), "price - price * 25bp = ?", "19.99 - 19.99 * 25bp = 19.940025"},
+		{Percent(NewFromFloatWithName("base", 4.5)).Add(BasisPoints(NewFromInt(25))), "base + 25bp = ?", "4.5% + 25bp = 0.0475"},
+		{price.Mul(Percent(NewFromInt(-5))), "price * -5% = ?", "19.99 * -5% = -0.9995"},
+		{
+			PercentChange(NewFromIntWithName("old", 80), NewFromIntWithName("new", 100)).SetName("change"),
+			"(new - old) / old = change",
+			"(100 - 80) / 80 = 25%",
+		},
+		{
+			PercentChange(NewFromIntWithName("old", 80), NewFromIntWithName("new", 60)),
+			"(new - old) / old = ?",
+			"(60 - 80) / 80 = -25%",
+		},
+	}
+
+	for _, tt := range tests {
+		vars, formula := tt.d.Math()
+		assert.Equal(t, tt.vars, vars)
+		assert.Equal(t, tt.formula, formula)
+
+		p, err := Parse(vars, leaves(tt.d))
+		require.NoError(t, err, vars)
+		pvars, _ := p.Math()
+		assert.Equal(t, vars, pvars)
+
+		p, err = Parse(formula, nil)
+		require.NoError(t, err, formula)
+		assert.True(t, p.Equal(tt.d), formula)
+		_, pformula := p.Math()
+		assert.Equal(t, formula, pformula)
+	}
+
+	assert.Equal(t, "-0.25", PercentChange(NewFromInt(100), NewFromInt(75)).String())
+	assert.Panics(t, func() { PercentChange(NewFromInt(0), NewFromInt(1)) })
+}
+
+func TestParsePercent(t *testing.T) {
+	tests := map[string]string{
+		"8.25%":         "0.0825",
+		"-5%":           "-0.05",
+		"25bp":          "0.0025",
+		"-25bp":         "-0.0025",
+		"200 * 50%":     "100",
+		"7 % 4":         "3",
+		"(100 + 5%)":    "100.05",
+		"1 = 10%":       "1",
+		"1 = -25bp":     "1",
+		"round(0)(5%)":  "0",
+		"sum(5%, 25bp)": "0.0525",
+	}
+
+	for expr, want := range tests {
+		d, err := Parse(expr, nil)
+		require.NoError(t, err, expr)
+		assert.Equal(t, want, d.String(), expr)
+		assert.Equal(t, "", d.GetName(), expr)
+	}
+
+	_, err := Parse("5%4", nil)
+	assert.EqualError(t, err, `tomath: unexpected "4" at offset 2`)
+	_, err = Parse("- 5xbp", nil)
+	assert.EqualError(t, err, `tomath: invalid number "-5xbp" at offset 2`)
+
+	d, err := Parse("price * rate", map[string]Decimal{
+		"price": NewFromInt(200),
+		"rate":  Percent(NewFromInt(5)),
+	})
+	require.NoError(t, err)
+	_, formula := d.Math()
+	assert.Equal(t, "200 * 5% = 10", formula)
+}
+
+func TestPercentRebind(t *testing.T) {
+	tax := NewFromIntWithName("price", 200).PercentOf(Percent(NewFromIntWithName("rate", 5)))
+	_, formula := tax.Rebind(map[string]Decimal{"rate": Percent(NewFromInt(8))}).Math()
+	assert.Equal(t, "200 * 8% = 16", formula)
+}
+
+func TestPercentRenderers(t *testing.T) {
+	d := NewFromIntWithName("price", 200).PercentOf(Percent(NewFromFloat(8.25))).SetName("tax")
+
+	vars, formula := d.MathLaTeX()
+	assert.Equal(t, `\mathrm{price} \cdot 8.25\% = \mathrm{tax}`, vars)
+	assert.Equal(t, `200 \cdot 8.25\% = 16.5`, formula)
+
+	vars, _ = d.MathML()
+	assert.Contains(t, vars, `<mi>price</mi><mo>&#xD7;</mo><mrow><mn>8.25</mn><mo>%</mo></mrow>`)
+
+	_, formula = BasisPoints(NewFromInt(25)).MathLaTeX()
+	assert.Equal(t, `25\,\mathrm{bp} = 25\,\mathrm{bp}`, formula)
+
+	_, formula = d.MathHTML()
+	assert.Contains(t, formula, `data-value="0.0825">8.25%</span>`)
+
+	assert.Equal(t, "price (200), times 8.25%", d.Explain())
+
+	vars, formula, err := d.MathWith(MathOptions{Fixed: true, Places: 1, Unnamed: PlaceholderError})
+	require.NoError(t, err)
+	assert.Equal(t, "price * 8.25% = tax", vars)
+	assert.Equal(t, "200.0 * 8.3% = 16.5", formula)
+}
@@ -0,0 +1,187 @@
+package tomath
+
+import (
+	"errors"
+	"strings"
+)
+
+// convertsTo separates the units in the name of a conversion factor, ex:
+// "MB->GB".
+const convertsTo = "->"
+
+// Quantity is a Decimal measured in a Unit. Its arithmetic is traced like the
+// one of a Decimal, multiplies and divides the units, rejects additions of
+// incompatible units and records the conversions between compatible units as
+// factors named after them.
+//
+// Example:
+//
+//     storage, _ := NewWithUnit("storage", "1.5", "GB")
+//     price, _ := NewWithUnit("price", "0.02", "USD/GB")
+//     storage.Mul(price).SetName("cost").Math()
+//     // output: "storage (1.5 GB) * price (0.02 USD/GB) = cost (0.03 USD)"
+//
+//     extra, _ := NewWithUnit("extra", "500", "MB")
+//     total, _ := storage.Add(extra)
+//     total.SetName("total").Math()
+//     // output: "storage (1.5 GB) + extra (500 MB) * MB->GB (0.001 GB/MB) = total (2 GB)"
+//
+// The zero value is a dimensionless 0.
+type Quantity struct {
+	value Decimal
+	unit  Unit
+}
+
+// NewWithUnit returns the quantity value named name measured in unit, see
+// ParseUnit().
+func NewWithUnit(name, value, unit string) (Quantity, error) {
+	d, err := NewFromStringWithName(name, value)
+	if err != nil {
+		return Quantity{}, err
+	}
+	return NewQuantity(d, unit)
+}
+
+// NewQuantity returns the quantity d measured in unit, see ParseUnit().
+func NewQuantity(d Decimal, unit string) (Quantity, error) {
+	u, err := ParseUnit(unit)
+	if err != nil {
+		return Quantity{}, err
+	}
+	return newQuantity(d, u), nil
+}
+
+// newQuantity returns d measured in u, which is recorded on d when it is a
+// leaf.
+func newQuantity(d Decimal, u Unit) Quantity {
+	if e := d.node(); e.op == OpLeaf {
+		leaf := *e
+		leaf.unit = u.String()
+		d.expr = &leaf
+	}
+	return Quantity{value: d, unit: u}
+}
+
+// Value returns the quantity as a Decimal.
+func (q Quantity) Value() Decimal {
+	return q.value
+}
+
+// Unit returns the unit of the quantity.
+func (q Quantity) Unit() Unit {
+	return q.unit
+}
+
+// SetName sets the name of the quantity.
+func (q Quantity) SetName(name string) Quantity {
+	q.value = q.value.SetName(name)
+	return q
+}
+
+// GetName gets the name of the quantity.
+func (q Quantity) GetName() string {
+	return q.value.name
+}
+
+// Add returns q + q2 in the unit of q. It returns an error if the units are not
+// compatible.
+func (q Quantity) Add(q2 Quantity) (Quantity, error) {
+	q2, err := q2.convert(q.unit)
+	if err != nil {
+		return Quantity{}, errors.New("tomath: cannot add " + q2.unit.String() + " to " + q.unit.String())
+	}
+	return Quantity{value: q.value.Add(q2.value), unit: q.unit}, nil
+}
+
+// Sub returns q - q2 in the unit of q. It returns an error if the units are not
+// compatible.
+func (q Quantity) Sub(q2 Quantity) (Quantity, error) {
+	q2, err := q2.convert(q.unit)
+	if err != nil {
+		return Quantity{}, errors.New("tomath: cannot subtract " + q2.unit.String() + " from " + q.unit.String())
+	}
+	return Quantity{value: q.value.Sub(q2.value), unit: q.unit}, nil
+}
+
+// Neg returns -q.
+func (q Quantity) Neg() Quantity {
+	return Quantity{value: q.value.Neg(), unit: q.unit}
+}
+
+// Mul returns q * q2 measured in the product of their units.
+func (q Quantity) Mul(q2 Quantity) Quantity {
+	return Quantity{value: q.value.Mul(q2.value), unit: q.unit.Mul(q2.unit)}
+}
+
+// Div returns q / q2 measured in the quotient of their units.
+func (q Quantity) Div(q2 Quantity) Quantity {
+	return Quantity{value: q.value.Div(q2.value), unit: q.unit.Div(q2.unit)}
+}
+
+// Pow returns q raised to the power n measured in its unit raised to n.
+func (q Quantity) Pow(n int) Quantity {
+	return Quantity{value: q.value.Pow(NewFromInt(int64(n))), unit: q.unit.Pow(n)}
+}
+
+// To returns q converted to unit, see ParseUnit(). The conversion multiplies q
+// by a factor named after the units, ex: "MB->GB", or divides it by the inverse
+// factor when the factor is not exact, ex: "q / min->h (60 min/h)". It returns
+// an error if the units are not compatible.
+func (q Quantity) To(unit string) (Quantity, error) {
+	u, err := ParseUnit(unit)
+	if err != nil {
+		return Quantity{}, err
+	}
+	return q.convert(u)
+}
+
+// convert returns q converted to u. On error, it returns q unchanged.
+func (q Quantity) convert(u Unit) (Quantity, error) {
+	if q.unit.Equal(u) {
+		return q, nil
+	}
+	if !q.unit.Compatible(u) {
+		return q, errors.New("tomath: cannot convert " + q.unit.String() + " to " + u.String())
+	}
+
+	name := q.unit.String() + convertsTo + u.String()
+	r := q.unit.factor()
+	r.Quo(r, u.factor())
+	if f, ok := exactDecimal(r); ok {
+		if f.Equal(one) {
+			return Quantity{value: q.value, unit: u}, nil
+		}
+		factor := newQuantity(NewFromDecimalWithName(name, f), u.Div(q.unit))
+		return Quantity{value: q.value.Mul(factor.value), unit: u}, nil
+	}
+
+	// The factors of the units only have 2, 3 and 5 as prime factors, the
+	// inverse of a ratio with a 3 in its denominator is exact.
+	f, _ := exactDecimal(r.Inv(r))
+	factor := newQuantity(NewFromDecimalWithName(name, f), q.unit.Div(u))
+	return Quantity{value: q.value.Div(factor.value), unit: u}, nil
+}
+
+// String returns the value followed by the unit, ex: "1.5 GB".
+func (q Quantity) String() string {
+	return withUnit(q.value.String(), q.unit.String())
+}
+
+// Math returns the formula underlying the quantity with the names of its values
+// followed by their value and unit, ex:
+// "storage (1.5 GB) * price (0.02 USD/GB) = cost (0.03 USD)".
+func (q Quantity) Math() string {
+	var b strings.Builder
+	q.value.node().render(&b, func(e *Expr) string {
+		return annotate(e.name, withUnit(e.value.String(), e.unit))
+	}, MathOptions{})
+	return b.String() + equal + annotate(q.value.name, q.String())
+}
+
+// withUnit renders a value followed by its unit, if any.
+func withUnit(value, unit string) string {
+	if unit == "" {
+		return value
+	}
+	return value + " " + unit
+}
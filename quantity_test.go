@@ -0,0 +1,108 @@
+package tomath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func requireQuantity(t *testing.T, name, value, unit string) Quantity {
+	t.Helper()
+	q, err := NewWithUnit(name, value, unit)
+	require.NoError(t, err)
+	return q
+}
+
+func TestQuantityMath(t *testing.T) {
+	storage := requireQuantity(t, "storage", "1.5", "GB")
+	price := requireQuantity(t, "price", "0.02", "USD/GB")
+
+	cost := storage.Mul(price).SetName("cost")
+	assert.Equal(t, "0.03 USD", cost.String())
+	assert.Equal(t, "storage (1.5 GB) * price (0.02 USD/GB) = cost (0.03 USD)", cost.Math())
+
+	vars, formula := cost.Value().Math()
+	assert.Equal(t, "storage * price = cost", vars)
+	assert.Equal(t, "1.5 * 0.02 = 0.03", formula)
+
+	hours := requireQuantity(t, "hours", "720", "h")
+	rate := cost.Div(hours)
+	assert.Equal(t, "USD/h", rate.Unit().String())
+
+	side := requireQuantity(t, "side", "2", "m")
+	area := side.Pow(2).SetName("area")
+	assert.Equal(t, "side (2 m)^2 = area (4 m^2)", area.Math())
+	assert.Equal(t, "1/m", side.Pow(-1).Unit().String())
+}
+
+func TestQuantityAdd(t *testing.T) {
+	storage := requireQuantity(t, "storage", "1.5", "GB")
+
+	total, err := storage.Add(requireQuantity(t, "extra", "500", "MB"))
+	require.NoError(t, err)
+	total = total.SetName("total")
+	assert.Equal(t, "2 GB", total.String())
+	assert.Equal(t, "storage (1.5 GB) + extra (500 MB) * MB->GB (0.001 GB/MB) = total (2 GB)", total.Math())
+
+	total, err = storage.Sub(requireQuantity(t, "used", "0.5", "GB"))
+	require.NoError(t, err)
+	assert.Equal(t, "storage (1.5 GB) - used (0.5 GB) = 1 GB", total.Math())
+
+	_, err = storage.Add(requireQuantity(t, "hours", "2", "h"))
+	assert.EqualError(t, err, "tomath: cannot add h to GB")
+
+	_, err = storage.Sub(requireQuantity(t, "price", "2", "USD/GB"))
+	assert.EqualError(t, err, "tomath: cannot subtract USD/GB from GB")
+
+	_, err = storage.Add(Quantity{})
+	assert.Error(t, err)
+}
+
+func TestQuantityTo(t *testing.T) {
+	tests := []struct {
+		value, from, to string
+		want            string
+	}{
+		{"1500", "MB", "GB", "q (1500 MB) * MB->GB (0.001 GB/MB) = 1.5 GB"},
+		{"90", "min", "h", "q (90 min) / min->h (60 min/h) = 1.5 h"},
+		{"1.5", "h", "min", "q (1.5 h) * h->min (60 min/h) = 90 min"},
+		{"36", "GB/h", "MB/min", "q (36 GB/h) / GB/h->MB/min (0.06 GB*min/h/MB) = 600 MB/min"},
+		{"1", "GiB", "MiB", "q (1 GiB) * GiB->MiB (1024 MiB/GiB) = 1024 MiB"},
+		{"2", "h", "hour", "q (2 h) = q (2 hour)"},
+		{"2", "GB", "GB", "q (2 GB) = q (2 GB)"},
+	}
+
+	for _, tt := range tests {
+		q, err := requireQuantity(t, "q", tt.value, tt.from).To(tt.to)
+		require.NoError(t, err, tt.want)
+		assert.Equal(t, tt.want, q.Math())
+	}
+
+	q, err := requireQuantity(t, "q", "90", "min").To("h")
+	require.NoError(t, err)
+	vars, formula := q.Value().Math()
+	assert.Equal(t, "q / `min->h` = ?", vars)
+	assert.Equal(t, "90 / 60 = 1.5", formula)
+
+	q = requireQuantity(t, "storage", "1.5", "GB")
+	_, err = q.To("h")
+	assert.EqualError(t, err, "tomath: cannot convert GB to h")
+	_, err = q.To("G B")
+	assert.EqualError(t, err, `tomath: invalid unit "G B"`)
+}
+
+func TestNewWithUnit(t *testing.T) {
+	_, err := NewWithUnit("storage", "abc", "GB")
+	assert.Error(t, err)
+
+	_, err = NewWithUnit("storage", "1", "GB/")
+	assert.EqualError(t, err, `tomath: invalid unit "GB/"`)
+
+	q, err := NewQuantity(NewFromFloat(2.5), "kg")
+	require.NoError(t, err)
+	assert.Equal(t, "", q.GetName())
+	assert.Equal(t, "2.5 kg = 2.5 kg", q.Math())
+	assert.Equal(t, "kg", q.Value().Expr().Unit())
+	assert.Equal(t, "-2.5 kg", q.Neg().String())
+}
@@ -0,0 +1,166 @@
+package tomath
+
+import (
+	"math/big"
+	"strconv"
+
+	"github.com/shopspring/decimal"
+)
+
+const roundToIncrement = "roundToIncrement"
+
+// RoundMode selects how a value is rounded to a number of digits.
+type RoundMode uint8
+
+const (
+	// RoundHalfUp rounds to the nearest neighbor and halves away from zero as
+	// Round() does.
+	RoundHalfUp RoundMode = iota
+	// RoundHalfEven rounds to the nearest neighbor and halves to the even
+	// neighbor as RoundBank() does.
+	RoundHalfEven
+	// RoundDown rounds toward zero as Truncate() does.
+	RoundDown
+	// RoundCeiling rounds toward positive infinity as Ceil() does.
+	RoundCeiling
+	// RoundFloor rounds toward negative infinity as Floor() does.
+	RoundFloor
+	// RoundHalfDown rounds to the nearest neighbor and halves toward zero.
+	RoundHalfDown
+	// RoundHalfOdd rounds to the nearest neighbor and halves to the odd
+	// neighbor.
+	RoundHalfOdd
+	// RoundUp rounds away from zero.
+	RoundUp
+	// Round05Up rounds away from zero when the last kept digit is 0 or 5 and
+	// toward zero otherwise, so that repeated roundings are not biased.
+	Round05Up
+)
+
+var roundModeNames = [...]string{
+	RoundHalfUp:   "halfUp",
+	RoundHalfEven: "halfEven",
+	RoundDown:     "down",
+	RoundCeiling:  "ceiling",
+	RoundFloor:    "floor",
+	RoundHalfDown: "halfDown",
+	RoundHalfOdd:  "halfOdd",
+	RoundUp:       "up",
+	Round05Up:     "05up",
+}
+
+// String returns the name of the rounding mode, ex: "halfEven".
+func (m RoundMode) String() string {
+	if int(m) < len(roundModeNames) {
+		return roundModeNames[m]
+	}
+	return "roundMode(" + strconv.Itoa(int(m)) + ")"
+}
+
+// valid returns whether m is one of the RoundMode constants.
+func (m RoundMode) valid() bool {
+	return int(m) < len(roundModeNames)
+}
+
+// lookupRoundMode returns the rounding mode named name.
+func lookupRoundMode(name string) (RoundMode, bool) {
+	for m, n := range roundModeNames {
+		if n == name {
+			return RoundMode(m), true
+		}
+	}
+	return 0, false
+}
+
+// RoundWith rounds the decimal to places decimal places in mode. If places < 0,
+// it rounds the integer part to the nearest 10^(-places).
+//
+// Example:
+//
+//     NewFromFloat(2.5).RoundWith(0, RoundHalfDown).String()  // output: "2"
+//     NewFromFloat(2.5).RoundWith(0, RoundHalfOdd).String()   // output: "3"
+//     NewFromFloat(-2.1).RoundWith(0, RoundUp).String()       // output: "-3"
+//     NewFromFloat(2.19).RoundWith(1, Round05Up).String()     // output: "2.1"
+//     NewFromFloat(2.09).RoundWith(1, Round05Up).String()     // output: "2.1"
+//
+// Math() renders the mode along with the places, ex: "round(2, halfEven)(var1)".
+func (d Decimal) RoundWith(places int32, mode RoundMode) Decimal {
+	return newOp(mode.quo(d.decimal, one, places), OpRound, []int32{places, int32(mode)}, d)
+}
+
+// RoundToIncrement rounds the decimal to a multiple of increment in mode. It
+// panics if increment is not positive.
+//
+// Example:
+//
+//     NewFromFloat(1.37).RoundToIncrement(NewFromFloat(0.25), RoundHalfEven).String() // output: "1.25"
+//     NewFromFloat(1.38).RoundToIncrement(NewFromFloat(0.25), RoundCeiling).String()  // output: "1.5"
+//
+// Math() renders the mode and the increment, ex:
+// "roundToIncrement(halfEven)(var1, var2)".
+func (d Decimal) RoundToIncrement(increment Decimal, mode RoundMode) Decimal {
+	if increment.decimal.Sign() <= 0 {
+		panic("tomath: rounding increment must be positive")
+	}
+	v := mode.quo(d.decimal, increment.decimal, 0).Mul(increment.decimal)
+	return newOp(v, OpRoundToIncrement, []int32{int32(mode)}, d, increment)
+}
+
+// roundMode returns the rounding mode parameter of the node, if any.
+func (e *Expr) roundMode() (RoundMode, bool) {
+	switch {
+	case e.op == OpRound && len(e.params) == 2:
+		return RoundMode(e.params[1]), true
+	case e.op == OpRoundToIncrement && len(e.params) == 1:
+		return RoundMode(e.params[0]), true
+	}
+	return 0, false
+}
+
+// quo returns x / y rounded to places digits after the decimal point.
+func (m RoundMode) quo(x, y decimal.Decimal, places int32) decimal.Decimal {
+	if !m.valid() {
+		panic("tomath: unknown rounding mode " + m.String())
+	}
+
+	q, r := x.QuoRem(y, places)
+	if r.IsZero() {
+		return q
+	}
+
+	sign := x.Sign() * y.Sign()
+	if m.away(q, places, sign, r.Abs().Mul(two).Cmp(y.Abs().Shift(-places))) {
+		q = q.Add(decimal.New(int64(sign), -places))
+	}
+
+	return q
+}
+
+// away returns whether an inexact quotient truncated to q is rounded away from
+// zero given its sign and the comparison of the discarded fraction with one
+// half of the last digit.
+func (m RoundMode) away(q decimal.Decimal, places int32, sign, half int) bool {
+	switch m {
+	case RoundHalfUp:
+		return half >= 0
+	case RoundHalfEven:
+		return half > 0 || half == 0 && q.Shift(places).BigInt().Bit(0) == 1
+	case RoundDown:
+		return false
+	case RoundCeiling:
+		return sign > 0
+	case RoundFloor:
+		return sign < 0
+	case RoundHalfDown:
+		return half > 0
+	case RoundHalfOdd:
+		return half > 0 || half == 0 && q.Shift(places).BigInt().Bit(0) == 0
+	case RoundUp:
+		return true
+	case Round05Up:
+		digit := new(big.Int).Mod(new(big.Int).Abs(q.Shift(places).BigInt()), big.NewInt(10)).Int64()
+		return digit == 0 || digit == 5
+	}
+
+	return half >= 0
+}
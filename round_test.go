@@ -0,0 +1,84 @@
+package tomath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundWith(t *testing.T) {
+	modes := []RoundMode{RoundHalfUp, RoundHalfEven, RoundDown, RoundCeiling, RoundFloor, RoundHalfDown, RoundHalfOdd, RoundUp, Round05Up}
+	tests := map[string][]string{
+		"5.5":  {"6", "6", "5", "6", "5", "5", "5", "6", "6"},
+		"2.5":  {"3", "2", "2", "3", "2", "2", "3", "3", "2"},
+		"1.6":  {"2", "2", "1", "2", "1", "2", "2", "2", "1"},
+		"1.1":  {"1", "1", "1", "2", "1", "1", "1", "2", "1"},
+		"1.0":  {"1", "1", "1", "1", "1", "1", "1", "1", "1"},
+		"0.4":  {"0", "0", "0", "1", "0", "0", "0", "1", "1"},
+		"-1.1": {"-1", "-1", "-1", "-1", "-2", "-1", "-1", "-2", "-1"},
+		"-1.6": {"-2", "-2", "-1", "-1", "-2", "-2", "-2", "-2", "-1"},
+		"-2.5": {"-3", "-2", "-2", "-2", "-3", "-2", "-3", "-3", "-2"},
+		"-5.5": {"-6", "-6", "-5", "-5", "-6", "-5", "-5", "-6", "-6"},
+	}
+
+	for in, want := range tests {
+		for i, mode := range modes {
+			assert.Equal(t, want[i], RequireFromString(in).RoundWith(0, mode).String(), in+" "+mode.String())
+		}
+	}
+
+	assert.Equal(t, "2.1", NewFromFloat(2.19).RoundWith(1, Round05Up).String())
+	assert.Equal(t, "2.1", NewFromFloat(2.09).RoundWith(1, Round05Up).String())
+	assert.Equal(t, "1300", NewFromInt(1250).RoundWith(-2, RoundHalfOdd).String())
+	assert.Panics(t, func() { NewFromInt(1).RoundWith(0, 42) })
+}
+
+func TestRoundWithMatchesRound(t *testing.T) {
+	for _, s := range []string{"5.45", "-5.45", "5.55", "0.005", "-12.3456", "7"} {
+		d := RequireFromString(s)
+		assert.Equal(t, d.Round(2).String(), d.RoundWith(2, RoundHalfUp).String(), s)
+		assert.Equal(t, d.RoundBank(2).String(), d.RoundWith(2, RoundHalfEven).String(), s)
+		assert.Equal(t, d.Truncate(1).String(), d.RoundWith(1, RoundDown).String(), s)
+		assert.Equal(t, d.Ceil().String(), d.RoundWith(0, RoundCeiling).String(), s)
+		assert.Equal(t, d.Floor().String(), d.RoundWith(0, RoundFloor).String(), s)
+	}
+}
+
+func TestRoundToIncrement(t *testing.T) {
+	quarter := NewFromFloat(0.25)
+	tests := map[string]Decimal{
+		"1.25":  NewFromFloat(1.37).RoundToIncrement(quarter, RoundHalfEven),
+		"1.5":   NewFromFloat(1.38).RoundToIncrement(quarter, RoundCeiling),
+		"1":     NewFromFloat(1.125).RoundToIncrement(quarter, RoundHalfEven),
+		"-1.25": NewFromFloat(-1.125).RoundToIncrement(quarter, RoundFloor),
+		"12.35": NewFromFloat(12.34).RoundToIncrement(NewFromFloat(0.05), RoundHalfUp),
+		"1500":  NewFromInt(1234).RoundToIncrement(NewFromInt(500), RoundUp),
+	}
+
+	for want, d := range tests {
+		assert.Equal(t, want, d.String())
+	}
+
+	assert.PanicsWithValue(t, "tomath: rounding increment must be positive", func() { NewFromInt(1).RoundToIncrement(NewFromInt(0), RoundUp) })
+}
+
+func TestRoundModeMath(t *testing.T) {
+	vars, formula := NewFromFloatWithName("var1", 2.345).RoundWith(2, RoundHalfDown).SetName("var2").Math()
+	assert.Equal(t, "round(2, halfDown)(var1) = var2", vars)
+	assert.Equal(t, "round(2, halfDown)(2.345) = 2.34", formula)
+
+	d := NewFromFloatWithName("price", 12.34).
+		RoundToIncrement(NewFromFloatWithName("tick", 0.05), Round05Up).
+		SetName("total")
+	vars, formula = d.Math()
+	assert.Equal(t, "roundToIncrement(05up)(price, tick) = total", vars)
+	assert.Equal(t, "roundToIncrement(05up)(12.34, 0.05) = 12.3", formula)
+
+	p, err := Parse(vars, map[string]Decimal{"price": NewFromFloat(12.34), "tick": NewFromFloat(0.05)})
+	require.NoError(t, err)
+	assert.Equal(t, "12.3", p.String())
+
+	assert.Equal(t, "price (12.34) rounded away from zero after a 0 or a 5 to a multiple of tick (0.05)", d.Explain())
+	assert.Equal(t, "div[prec=1, round=halfOdd](2, 8) = 0.3", Context{Precision: 1, Rounding: RoundHalfOdd}.Div(NewFromInt(2), NewFromInt(8)).Steps()[0].String())
+}
@@ -0,0 +1,130 @@
+package tomath
+
+import (
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+const scopeSeparator = "."
+
+// Scope is a namespace for the names of decimals. Names created through a
+// scope are prefixed with its dotted path, ex: "tax.state.rate", so that
+// helpers composing formulas can name their intermediates without colliding
+// with the names of their callers.
+//
+// Example:
+//
+//     func timesOneHundred(s Scope, input Decimal) Decimal {
+//         s = s.Scope("timesOneHundred")
+//         return s.ResolveTo(input.Mul(s.NewFromInt("oneHundred", 100)), "result")
+//     }
+//
+//     d := timesOneHundred(NewScope("tax"), NewFromFloatWithName("rate", 0.07))
+//     d.GetName() // output: "tax.timesOneHundred.result"
+//
+// The zero value is the root scope which leaves names untouched.
+type Scope struct {
+	path string
+}
+
+// NewScope returns the scope with the given path, ex: NewScope("tax", "state")
+// is the scope "tax.state".
+func NewScope(path ...string) Scope {
+	return Scope{path: strings.Join(path, scopeSeparator)}
+}
+
+// Scope returns the scope named name nested in s.
+func (s Scope) Scope(name string) Scope {
+	return Scope{path: s.Name(name)}
+}
+
+// String returns the path of the scope.
+func (s Scope) String() string {
+	return s.path
+}
+
+// Name returns name prefixed with the path of the scope.
+func (s Scope) Name(name string) string {
+	if s.path == "" {
+		return name
+	}
+	return s.path + scopeSeparator + name
+}
+
+// Contains returns whether name belongs to the scope or to one of its nested
+// scopes. The root scope contains every name.
+func (s Scope) Contains(name string) bool {
+	return s.path == "" || strings.HasPrefix(name, s.path+scopeSeparator)
+}
+
+// New returns a new fixed-point decimal, value * 10 ^ exp, named name in the
+// scope.
+func (s Scope) New(name string, value int64, exp int32) Decimal {
+	return NewWithName(s.Name(name), value, exp)
+}
+
+// NewFromInt converts a int64 to Decimal named name in the scope.
+func (s Scope) NewFromInt(name string, value int64) Decimal {
+	return NewFromIntWithName(s.Name(name), value)
+}
+
+// NewFromString returns a new Decimal from a string representation named name
+// in the scope.
+func (s Scope) NewFromString(name string, value string) (Decimal, error) {
+	return NewFromStringWithName(s.Name(name), value)
+}
+
+// NewFromFloat converts a float64 to Decimal named name in the scope.
+func (s Scope) NewFromFloat(name string, value float64) Decimal {
+	return NewFromFloatWithName(s.Name(name), value)
+}
+
+// NewFromDecimal converts a github.com/shopspring/decimal#Decimal to Decimal
+// named name in the scope.
+func (s Scope) NewFromDecimal(name string, d decimal.Decimal) Decimal {
+	return NewFromDecimalWithName(s.Name(name), d)
+}
+
+// SetName sets the name of d to name in the scope.
+func (s Scope) SetName(d Decimal, name string) Decimal {
+	return d.SetName(s.Name(name))
+}
+
+// ResolveTo resolves d to name in the scope.
+func (s Scope) ResolveTo(d Decimal, name string) Decimal {
+	return d.ResolveTo(s.Name(name))
+}
+
+// collapse returns e in which the operations, other than e itself and the
+// expanded ones, named in one of the scopes are replaced by a leaf holding their
+// name and value.
+func collapse(e *Expr, scopes []Scope) *Expr {
+	if len(scopes) == 0 || e.op == OpLeaf {
+		return e
+	}
+
+	c := *e
+	c.operands = make([]*Expr, len(e.operands))
+	for i, o := range e.operands {
+		if o.op != OpLeaf && !o.expanded && inScopes(o.name, scopes) {
+			c.operands[i] = &Expr{name: o.name, value: o.value, notation: o.notation}
+		} else {
+			c.operands[i] = collapse(o, scopes)
+		}
+	}
+
+	return &c
+}
+
+func inScopes(name string, scopes []Scope) bool {
+	if name == "" {
+		return false
+	}
+	for _, s := range scopes {
+		if s.Contains(name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package tomath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScopeNames(t *testing.T) {
+	tax := NewScope("tax")
+	state := tax.Scope("state")
+
+	assert.Equal(t, "tax.state", state.String())
+	assert.Equal(t, "tax.state.rate", state.Name("rate"))
+	assert.Equal(t, state, NewScope("tax", "state"))
+	assert.Equal(t, "rate", Scope{}.Name("rate"))
+
+	assert.True(t, tax.Contains("tax.state.rate"))
+	assert.True(t, state.Contains("tax.state.rate"))
+	assert.False(t, state.Contains("tax.state"))
+	assert.False(t, tax.Contains("taxes.rate"))
+	assert.True(t, Scope{}.Contains("rate"))
+}
+
+func TestScopeConstructors(t *testing.T) {
+	s := NewScope("invoice", "line")
+
+	d, err := s.NewFromString("price", "1.5")
+	require.NoError(t, err)
+
+	for name, d := range map[string]Decimal{
+		"invoice.line.price":    d,
+		"invoice.line.quantity": s.NewFromInt("quantity", 3),
+		"invoice.line.rate":     s.NewFromFloat("rate", 0.07),
+		"invoice.line.fee":      s.New("fee", 1, 0),
+		"invoice.line.discount": s.NewFromDecimal("discount", NewFromInt(2).Decimal()),
+		"invoice.line.total":    s.SetName(d.Mul(NewFromInt(3)), "total"),
+	} {
+		assert.Equal(t, name, d.GetName())
+	}
+
+	resolved := s.ResolveTo(d.Mul(NewFromInt(3)), "total")
+	vars, _ := resolved.Math()
+	assert.Equal(t, "invoice.line.total = invoice.line.total", vars)
+}
+
+func TestScopeCollapse(t *testing.T) {
+	timesOneHundred := func(s Scope, input Decimal) Decimal {
+		s = s.Scope("timesOneHundred")
+		return s.SetName(input.Mul(s.NewFromInt("oneHundred", 100)), "result")
+	}
+
+	tax := NewScope("tax")
+	rate := tax.NewFromFloat("rate", 0.07)
+	d := NewFromFloatWithName("price", 20).
+		Mul(timesOneHundred(tax, rate)).
+		Div(timesOneHundred(Scope{}, NewFromIntWithName("hundred", 1))).
+		SetName("tax.amount")
+
+	tests := []struct {
+		collapse []Scope
+		vars     string
+		formula  string
+	}{
+		{nil, "price * (tax.rate * tax.timesOneHundred.oneHundred) / (hundred * timesOneHundred.oneHundred) = tax.amount", "20 * (0.07 * 100) / (1 * 100) = 1.4"},
+		{[]Scope{tax}, "price * tax.timesOneHundred.result / (hundred * timesOneHundred.oneHundred) = tax.amount", "20 * 7 / (1 * 100) = 1.4"},
+		{[]Scope{tax.Scope("timesOneHundred")}, "price * tax.timesOneHundred.result / (hundred * timesOneHundred.oneHundred) = tax.amount", "20 * 7 / (1 * 100) = 1.4"},
+		{[]Scope{NewScope("timesOneHundred"), tax}, "price * tax.timesOneHundred.result / timesOneHundred.result = tax.amount", "20 * 7 / 100 = 1.4"},
+		{[]Scope{{}}, "price * tax.timesOneHundred.result / timesOneHundred.result = tax.amount", "20 * 7 / 100 = 1.4"},
+		{[]Scope{NewScope("tax", "state")}, "price * (tax.rate * tax.timesOneHundred.oneHundred) / (hundred * timesOneHundred.oneHundred) = tax.amount", "20 * (0.07 * 100) / (1 * 100) = 1.4"},
+	}
+
+	for _, test := range tests {
+		vars, formula, err := d.MathWith(MathOptions{Collapse: test.collapse})
+		require.NoError(t, err)
+		assert.Equal(t, test.vars, vars)
+		assert.Equal(t, test.formula, formula)
+	}
+}
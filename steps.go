@@ -0,0 +1,160 @@
+package tomath
+
+import (
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+type (
+	// Step is a single operation of the computation underlying a Decimal.
+	Step struct {
+		Op     Op
+		Params []int32
+		Inputs []StepValue
+		// Outputs holds the result of the operation. QuoRem outputs the quotient
+		// followed by the remainder and its Params only hold the precision.
+		Outputs []StepValue
+	}
+
+	// StepValue is an input or an output of a Step. Name is empty for unnamed
+	// values.
+	StepValue struct {
+		Name  string
+		Value decimal.Decimal
+	}
+
+	// Steps is the ordered list of operations of a computation.
+	Steps []Step
+)
+
+// Steps returns the operations underlying the decimal in the order they were
+// evaluated. A result used several times as an operand is only evaluated once,
+// as is the division yielding both the quotient and the remainder of a QuoRem.
+//
+// Example:
+//
+//     steps := NewFromFloatWithName("var1", 1.1).
+//         Round(1).
+//         Add(NewFromFloatWithName("var2", 1)).
+//         Div(NewFromFloatWithName("var3", 2)).
+//         Steps()
+//     steps.String()
+//     // round(1)(1.1) = 1.1
+//     // 1.1 + 1 = 2.1
+//     // 2.1 / 2 = 1.05
+func (d Decimal) Steps() Steps {
+	return d.node().steps(exprName)
+}
+
+// StepsWith returns the operations underlying the decimal as Steps() does,
+// naming the unnamed values according to unnamed. It returns an error when
+// unnamed is not one of the Placeholder constants, or an *UnnamedError when it
+// is PlaceholderError and a value, an intermediate result or the result has no
+// name.
+//
+// Example:
+//
+//     steps, err := NewFromFloatWithName("a", 1).
+//         Add(NewFromFloatWithName("b", 2)).
+//         Mul(NewFromFloatWithName("c", 3)).
+//         StepsWith(PlaceholderGenerate)
+//     // steps[0].Outputs[0].Name: "t2"
+//     // steps[1].Inputs[0].Name:  "t2"
+//     // steps[1].Outputs[0].Name: "t1"
+func (d Decimal) StepsWith(unnamed Placeholder) (Steps, error) {
+	name, err := valueNames(d.node(), d.name, unnamed)
+	if err != nil {
+		return nil, err
+	}
+	return d.node().steps(name), nil
+}
+
+// steps returns the operations of e naming their inputs and outputs with name.
+func (e *Expr) steps(name func(*Expr) string) Steps {
+	var steps Steps
+	seen := map[*Expr]bool{}
+	divisions := map[quoRemKey]int{}
+
+	var visit func(e *Expr)
+	visit = func(e *Expr) {
+		if e.op == OpLeaf || seen[e] {
+			return
+		}
+		seen[e] = true
+
+		var key quoRemKey
+		if e.op == OpQuoRem {
+			key = quoRemKey{e.operands[0], e.operands[1], e.params[0]}
+			if i, ok := divisions[key]; ok {
+				if out := &steps[i].Outputs[e.params[1]]; out.Name == "" {
+					out.Name = name(e)
+				}
+				return
+			}
+		}
+
+		step := Step{Op: e.op, Params: e.Params()}
+		if e.op == OpQuoRem {
+			step.Params = step.Params[:1]
+		}
+		for _, o := range e.operands {
+			visit(o)
+			step.Inputs = append(step.Inputs, StepValue{Name: name(o), Value: o.value})
+		}
+
+		if e.op == OpQuoRem {
+			q, r := e.operands[0].value.QuoRem(e.operands[1].value, e.params[0])
+			step.Outputs = []StepValue{{Value: q}, {Value: r}}
+			step.Outputs[e.params[1]].Name = name(e)
+		} else {
+			step.Outputs = []StepValue{{Name: name(e), Value: e.value}}
+		}
+
+		if e.op == OpQuoRem {
+			divisions[key] = len(steps)
+		}
+		steps = append(steps, step)
+	}
+	visit(e)
+
+	return steps
+}
+
+// quoRemKey identifies the division of a QuoRem shared by its quotient and its
+// remainder.
+type quoRemKey struct {
+	d, d2     *Expr
+	precision int32
+}
+
+// String renders the step with the values of its inputs and outputs, ex:
+// "1.1 + 1 = 2.1" or "quoRem(3)(4.333 / 2.7) = 1.604, 0.0022".
+func (s Step) String() string {
+	e := &Expr{op: s.Op, params: s.Params, operands: make([]*Expr, len(s.Inputs))}
+	for i, in := range s.Inputs {
+		e.operands[i] = &Expr{name: in.Name, value: in.Value}
+	}
+
+	var b strings.Builder
+	e.render(&b, leafValue, MathOptions{})
+	b.WriteString(equal)
+	for i, out := range s.Outputs {
+		if i > 0 {
+			b.WriteString(comma)
+		}
+		b.WriteString(out.Value.String())
+	}
+
+	return b.String()
+}
+
+// String renders one step per line.
+func (s Steps) String() string {
+	var b strings.Builder
+	for _, step := range s {
+		b.WriteString(step.String())
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,135 @@
+package tomath
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSteps(t *testing.T) {
+	d := NewFromFloatWithName("var1", 1.1).
+		Round(1).
+		Add(NewFromFloatWithName("var2", 1)).
+		Add(NewFromFloatWithName("var2", 1)).
+		Div(NewFromFloatWithName("var3", 2)).
+		Mul(NewFromFloatWithName("var4", 2)).
+		SetName("var5")
+
+	assert.Equal(t, `round(1)(1.1) = 1.1
+1.1 + 1 = 2.1
+2.1 + 1 = 3.1
+3.1 / 2 = 1.55
+1.55 * 2 = 3.1
+`, d.Steps().String())
+
+	steps := d.Steps()
+	require.Len(t, steps, 5)
+	assert.Equal(t, Step{
+		Op:      OpRound,
+		Params:  []int32{1},
+		Inputs:  []StepValue{{Name: "var1", Value: decimal.RequireFromString("1.1")}},
+		Outputs: []StepValue{{Value: decimal.RequireFromString("1.1")}},
+	}, steps[0])
+	assert.Equal(t, []StepValue{{Value: decimal.RequireFromString("1.1")}, {Name: "var2", Value: decimal.NewFromInt(1)}}, steps[1].Inputs)
+	require.Len(t, steps[4].Outputs, 1)
+	assert.Equal(t, "var5", steps[4].Outputs[0].Name)
+	assert.Equal(t, "3.1", steps[4].Outputs[0].Value.String())
+}
+
+func TestStepsQuoRem(t *testing.T) {
+	_, r := NewFromFloatWithName("var1", 4.333).QuoRem(NewFromFloatWithName("var2", 2.7), 3)
+	d := r.Add(NewFromIntWithName("var3", 1))
+
+	steps := d.Steps()
+	assert.Equal(t, "quoRem(3)(4.333 / 2.7) = 1.604, 0.0022\n0.0022 + 1 = 1.0022\n", steps.String())
+	assert.Equal(t, []StepValue{
+		{Value: decimal.RequireFromString("1.604")},
+		{Name: "var1var2Remainder", Value: decimal.RequireFromString("0.0022")},
+	}, steps[0].Outputs)
+	assert.Equal(t, "var1var2Remainder", steps[1].Inputs[0].Name)
+}
+
+func TestStepsQuoRemShared(t *testing.T) {
+	q, r := NewFromIntWithName("var1", 7).QuoRem(NewFromIntWithName("var2", 2), 0)
+	q2, _ := NewFromIntWithName("var1", 7).QuoRem(NewFromIntWithName("var2", 2), 1)
+
+	steps := q.Add(r).Add(q2).Steps()
+	assert.Equal(t, `quoRem(0)(7 / 2) = 3, 1
+3 + 1 = 4
+quoRem(1)(7 / 2) = 3.5, 0
+4 + 3.5 = 7.5
+`, steps.String())
+	assert.Equal(t, []StepValue{
+		{Name: "var1var2Quotient", Value: decimal.NewFromInt(3)},
+		{Name: "var1var2Remainder", Value: decimal.NewFromInt(1)},
+	}, steps[0].Outputs)
+
+	steps = r.Add(q).Steps()
+	assert.Equal(t, "quoRem(0)(7 / 2) = 3, 1\n1 + 3 = 4\n", steps.String())
+}
+
+func TestStepsOperations(t *testing.T) {
+	var1 := NewWithName("var1", -2, 0)
+	var2 := NewWithName("var2", 3, 0)
+	shared := var1.Abs()
+
+	d := Sum(shared.Pow(var2), shared.Neg(), var2.Shift(1).DivRound(var1, 1), Max(var1, var2).Mod(var2))
+
+	assert.Equal(t, `abs(-2) = 2
+2^3 = 8
+neg(2) = -2
+shift(1)(3) = 30
+divRound(1)(30 / -2) = -15
+max(-2, 3) = 3
+3 % 3 = 0
+sum(8, -2, -15, 0) = -9
+`, d.Steps().String())
+}
+
+func TestStepsLeaf(t *testing.T) {
+	assert.Empty(t, NewWithName("var1", 1, 0).Steps())
+	assert.Equal(t, "", Decimal{}.Steps().String())
+}
+
+func TestStepsWith(t *testing.T) {
+	two := NewFromInt(2)
+	d := NewFromIntWithName("a", 1).Add(two).Mul(NewFromIntWithName("c", 3)).Sub(two)
+
+	vars, _, err := d.MathWith(MathOptions{Unnamed: PlaceholderGenerate})
+	require.NoError(t, err)
+	assert.Equal(t, "(a + t1) * c - t1 = t2", vars)
+
+	steps, err := d.StepsWith(PlaceholderGenerate)
+	require.NoError(t, err)
+	require.Len(t, steps, 3)
+	assert.Equal(t, []StepValue{{Name: "a", Value: decimal.NewFromInt(1)}, {Name: "t1", Value: decimal.NewFromInt(2)}}, steps[0].Inputs)
+	assert.Equal(t, []StepValue{{Name: "t3", Value: decimal.NewFromInt(3)}}, steps[0].Outputs)
+	assert.Equal(t, "t3", steps[1].Inputs[0].Name)
+	assert.Equal(t, []StepValue{{Name: "t4", Value: decimal.NewFromInt(9)}}, steps[1].Outputs)
+	assert.Equal(t, []StepValue{{Name: "t2", Value: decimal.NewFromInt(7)}}, steps[2].Outputs)
+
+	// the names are stable and skip the names of the computation
+	steps2, err := d.StepsWith(PlaceholderGenerate)
+	require.NoError(t, err)
+	assert.Equal(t, steps, steps2)
+	steps, err = NewFromIntWithName("t1", 1).Add(two).Mul(two).SetName("total").StepsWith(PlaceholderGenerate)
+	require.NoError(t, err)
+	assert.Equal(t, "t2", steps[0].Inputs[1].Name)
+	assert.Equal(t, "t3", steps[0].Outputs[0].Name)
+	assert.Equal(t, "total", steps[1].Outputs[0].Name)
+
+	for _, p := range []Placeholder{PlaceholderUnknown, PlaceholderValue} {
+		steps, err := d.StepsWith(p)
+		require.NoError(t, err)
+		assert.Equal(t, d.Steps(), steps)
+	}
+
+	_, err = d.StepsWith(PlaceholderError)
+	assert.EqualError(t, err, "tomath: unnamed values 2 and intermediates 3, 9 and result")
+	_, err = NewFromIntWithName("a", 1).Add(NewFromIntWithName("b", 2)).SetName("c").Mul(NewFromIntWithName("d", 3)).SetName("e").StepsWith(PlaceholderError)
+	assert.NoError(t, err)
+	_, err = d.StepsWith(4)
+	assert.EqualError(t, err, "tomath: unknown placeholder 4")
+}
@@ -24,10 +24,9 @@
 package tomath
 
 import (
+	"bytes"
 	"database/sql/driver"
 	"math/big"
-	"strconv"
-	"strings"
 
 	"github.com/shopspring/decimal"
 )
@@ -43,6 +42,8 @@ const (
 	shift      = "shift"
 	div        = " / "
 	quoRem     = "quoRem"
+	rem        = "rem"
+	remainder  = "Remainder"
 	divRound   = "divRound"
 	mod        = " % "
 	pow        = "^"
@@ -61,18 +62,37 @@ const (
 	sin        = "sin"
 	cos        = "cos"
 	tan        = "tan"
+	sqrt       = "sqrt"
+	exp        = "exp"
+	ln         = "ln"
+	log10      = "log10"
+	log        = "log"
+	asin       = "asin"
+	acos       = "acos"
+	atan2      = "atan2"
+	sinh       = "sinh"
+	cosh       = "cosh"
+	tanh       = "tanh"
+	asinh      = "asinh"
+	acosh      = "acosh"
+	atanh      = "atanh"
+	radians    = "radians"
+	degrees    = "degrees"
 	equal      = " = "
+	unknown    = "?"
 )
 
 type (
 	// Decimal represents a fixed-point decimal. It is immutable.
 	// number = value * 10 ^ exp
+	//
+	// Every Decimal carries the expression tree of the operations which
+	// produced it. See Expr().
 	Decimal struct {
-		parens  bool
 		name    string
 		decimal decimal.Decimal
-		vars    string
-		formula string
+		expr    *Expr
+		context *Context
 	}
 
 	// NullDecimal represents a nullable decimal with compatibility for
@@ -84,19 +104,28 @@ type (
 )
 
 var (
-	Zero = Decimal{
-		decimal: decimal.Zero,
-		name:    "zero",
-		vars:    "zero",
-		formula: "0",
-	}
+	Zero = newLeaf("zero", decimal.Zero)
+
+	// MarshalEnvelope specifies whether the JSON, text, binary and gob encodings
+	// of a Decimal carry its name, its formula and the expression tree underlying
+	// it instead of only its value. Decoding accepts both encodings regardless.
+	//
+	// It applies to every Decimal encoded by the process and is not safe to
+	// change while encodings run concurrently: set it once at initialization,
+	// or wrap the values to encode in an Envelope instead.
+	MarshalEnvelope = false
 )
 
 // SetName sets the name of the Decimal
 func (d Decimal) SetName(name string) Decimal {
 	d.name = name
-	if d.vars == "" {
-		d.vars = name
+	switch {
+	case d.expr == nil:
+		d.expr = &Expr{name: name, value: d.decimal}
+	case d.expr.op != OpLeaf || d.expr.name == "":
+		e := *d.expr
+		e.name = name
+		d.expr = &e
 	}
 	return d
 }
@@ -106,15 +135,18 @@ func (d Decimal) GetName() string {
 	return d.name
 }
 
-// Resolve removes the underlying math from the decimal and replaces it with the
-// current name and value.
+// Resolve hides the underlying math of the decimal behind the current name and
+// value. Math() renders the resolved decimal by its name and value only while
+// MathWith() can expand it back, see MathOptions.Expand.
 func (d Decimal) Resolve() Decimal {
-	return Decimal{
-		name:    d.name,
-		vars:    d.name,
-		formula: d.String(),
-		decimal: d.decimal,
+	r := newLeaf(d.name, d.decimal)
+	r.context = d.context
+	if e := d.node(); e.op != OpLeaf {
+		r.expr.resolved = e
+	} else {
+		r.expr.resolved = e.resolved
 	}
+	return r
 }
 
 // ResolveTo is a wrapper around SetName() and Resolve().
@@ -131,32 +163,20 @@ func (d Decimal) Decimal() decimal.Decimal {
 // first uses the decimal names. The second uses the decimal values. Both are
 // follwed by an equals sign with the current name and value respectively.
 func (d Decimal) Math() (string, string) {
-	if d.name == "" {
-		d.name = "?"
-	}
-
-	if d.vars == "" {
-		d.vars = "?"
-	}
-
-	if d.formula == "" {
-		d.formula = d.String()
-	}
-
-	return d.vars + equal + d.name,
-		d.formula + equal + d.String()
+	vars, formula, _ := d.MathWith(MathOptions{})
+	return vars, formula
 }
 
 // New returns a new fixed-point decimal, value * 10 ^ exp.
 func New(value int64, exp int32) Decimal {
 	d := decimal.New(value, exp)
-	return Decimal{decimal: d, formula: d.String()}
+	return newLeaf("", d)
 }
 
 // NewWithName returns a new fixed-point decimal, value * 10 ^ exp with a given name.
 func NewWithName(name string, value int64, exp int32) Decimal {
 	d := decimal.New(value, exp)
-	return Decimal{name: name, decimal: d, vars: name, formula: d.String()}
+	return newLeaf(name, d)
 }
 
 // NewFromInt converts a int64 to Decimal.
@@ -167,7 +187,7 @@ func NewWithName(name string, value int64, exp int32) Decimal {
 //     NewFromInt(-10).String() // output: "-10"
 func NewFromInt(value int64) Decimal {
 	d := decimal.NewFromInt(value)
-	return Decimal{decimal: d, formula: d.String()}
+	return newLeaf("", d)
 }
 
 // NewFromIntWithName converts a int64 to Decimal with a given name.
@@ -178,7 +198,7 @@ func NewFromInt(value int64) Decimal {
 //     NewFromIntWithName("var1", -10).String() // output: "-10"
 func NewFromIntWithName(name string, value int64) Decimal {
 	d := decimal.NewFromInt(value)
-	return Decimal{name: name, decimal: d, vars: name, formula: d.String()}
+	return newLeaf(name, d)
 }
 
 // NewFromInt32 converts a int32 to Decimal.
@@ -189,7 +209,7 @@ func NewFromIntWithName(name string, value int64) Decimal {
 //     NewFromInt(-10).String() // output: "-10"
 func NewFromInt32(value int32) Decimal {
 	d := decimal.NewFromInt32(value)
-	return Decimal{decimal: d, formula: d.String()}
+	return newLeaf("", d)
 }
 
 // NewFromInt32WithName converts a int32 to Decimal with a given name.
@@ -200,20 +220,20 @@ func NewFromInt32(value int32) Decimal {
 //     NewFromInt32WithName("var1", -10).String() // output: "-10"
 func NewFromInt32WithName(name string, value int32) Decimal {
 	d := decimal.NewFromInt32(value)
-	return Decimal{name: name, decimal: d, vars: name, formula: d.String()}
+	return newLeaf(name, d)
 }
 
 // NewFromBigInt returns a new Decimal from a big.Int, value * 10 ^ exp
 func NewFromBigInt(value *big.Int, exp int32) Decimal {
 	d := decimal.NewFromBigInt(value, exp)
-	return Decimal{decimal: d, formula: d.String()}
+	return newLeaf("", d)
 }
 
 // NewFromBigIntWithName returns a new Decimal from a big.Int, value * 10 ^ exp
 // with a given name
 func NewFromBigIntWithName(name string, value *big.Int, exp int32) Decimal {
 	d := decimal.NewFromBigInt(value, exp)
-	return Decimal{name: name, decimal: d, vars: name, formula: d.String()}
+	return newLeaf(name, d)
 }
 
 // NewFromString returns a new Decimal from a string representation.
@@ -231,7 +251,7 @@ func NewFromString(value string) (Decimal, error) {
 		return Decimal{}, err
 	}
 
-	return Decimal{decimal: d, formula: d.String()}, nil
+	return newLeaf("", d), nil
 }
 
 // NewFromStringWithName returns a new Decimal from a string representation with
@@ -249,7 +269,7 @@ func NewFromStringWithName(name string, value string) (Decimal, error) {
 		return Decimal{}, err
 	}
 
-	return Decimal{name: name, decimal: d, vars: name, formula: d.String()}, nil
+	return newLeaf(name, d), nil
 }
 
 // RequireFromString returns a new Decimal from a string representation
@@ -262,7 +282,7 @@ func NewFromStringWithName(name string, value string) (Decimal, error) {
 //
 func RequireFromString(value string) Decimal {
 	d := decimal.RequireFromString(value)
-	return Decimal{decimal: d, formula: d.String()}
+	return newLeaf("", d)
 }
 
 // RequireFromStringWithName returns a new Decimal from a string representation
@@ -275,7 +295,7 @@ func RequireFromString(value string) Decimal {
 //
 func RequireFromStringWithName(name string, value string) Decimal {
 	d := decimal.RequireFromString(value)
-	return Decimal{name: name, decimal: d, vars: name, formula: d.String()}
+	return newLeaf(name, d)
 }
 
 // NewFromFloat converts a float64 to Decimal.
@@ -290,7 +310,7 @@ func RequireFromStringWithName(name string, value string) Decimal {
 // NOTE: this will panic on NaN, +/-inf
 func NewFromFloat(value float64) Decimal {
 	d := decimal.NewFromFloat(value)
-	return Decimal{decimal: d, formula: d.String()}
+	return newLeaf("", d)
 }
 
 // NewFromFloatWithName converts a float64 to Decimal with a given name.
@@ -305,7 +325,7 @@ func NewFromFloat(value float64) Decimal {
 // NOTE: this will panic on NaN, +/-inf
 func NewFromFloatWithName(name string, value float64) Decimal {
 	d := decimal.NewFromFloat(value)
-	return Decimal{name: name, decimal: d, vars: name, formula: d.String()}
+	return newLeaf(name, d)
 }
 
 // NewFromFloat32 converts a float32 to Decimal.
@@ -320,7 +340,7 @@ func NewFromFloatWithName(name string, value float64) Decimal {
 // NOTE: this will panic on NaN, +/-inf
 func NewFromFloat32(value float32) Decimal {
 	d := decimal.NewFromFloat32(value)
-	return Decimal{decimal: d, formula: d.String()}
+	return newLeaf("", d)
 }
 
 // NewFromFloat32WithName converts a float32 to Decimal with a given name.
@@ -335,7 +355,7 @@ func NewFromFloat32(value float32) Decimal {
 // NOTE: this will panic on NaN, +/-inf
 func NewFromFloat32WithName(name string, value float32) Decimal {
 	d := decimal.NewFromFloat32(value)
-	return Decimal{name: name, decimal: d, vars: name, formula: d.String()}
+	return newLeaf(name, d)
 }
 
 // NewFromFloatWithExponent converts a float64 to Decimal, with an arbitrary
@@ -347,7 +367,7 @@ func NewFromFloat32WithName(name string, value float32) Decimal {
 //
 func NewFromFloatWithExponent(value float64, exp int32) Decimal {
 	d := decimal.NewFromFloatWithExponent(value, exp)
-	return Decimal{decimal: d, formula: d.String()}
+	return newLeaf("", d)
 }
 
 // NewFromFloatWithExponentWithName converts a float64 to Decimal with a given name, with an arbitrary
@@ -359,83 +379,43 @@ func NewFromFloatWithExponent(value float64, exp int32) Decimal {
 //
 func NewFromFloatWithExponentWithName(name string, value float64, exp int32) Decimal {
 	d := decimal.NewFromFloatWithExponent(value, exp)
-	return Decimal{name: name, decimal: d, vars: name, formula: d.String()}
+	return newLeaf(name, d)
 }
 
 // NewFromDecimal returns a new Decimal from github.com/shopspring/decimal#Decimal.
 func NewFromDecimal(d decimal.Decimal) Decimal {
-	return Decimal{decimal: d, formula: d.String()}
+	return newLeaf("", d)
 }
 
 // NewFromDecimalWithName returns a new Decimal from github.com/shopspring/decimal#Decimal
 // with a given name.
 func NewFromDecimalWithName(name string, d decimal.Decimal) Decimal {
-	return Decimal{name: name, decimal: d, vars: name, formula: d.String()}
+	return newLeaf(name, d)
 }
 
 // Abs returns the absolute value of the decimal.
 func (d Decimal) Abs() Decimal {
-	return Decimal{
-		decimal: d.decimal.Abs(),
-		vars:    abs + leftParen + d.vars + rightParen,
-		formula: abs + leftParen + d.formula + rightParen,
-	}
+	return newOp(d.decimal.Abs(), OpAbs, nil, d)
 }
 
 // Add returns d + d2.
 func (d Decimal) Add(d2 Decimal) Decimal {
-	return Decimal{
-		decimal: d.decimal.Add(d2.decimal),
-		vars:    d.vars + add + d2.vars,
-		formula: d.formula + add + d2.formula,
-		parens:  true,
-	}
+	return newOp(d.decimal.Add(d2.decimal), OpAdd, nil, d, d2)
 }
 
 // Sub returns d - d2.
 func (d Decimal) Sub(d2 Decimal) Decimal {
-	return Decimal{
-		decimal: d.decimal.Sub(d2.decimal),
-		vars:    d.vars + sub + d2.vars,
-		formula: d.formula + sub + d2.formula,
-		parens:  true,
-	}
+	return newOp(d.decimal.Sub(d2.decimal), OpSub, nil, d, d2)
 }
 
 // Neg returns -d.
 func (d Decimal) Neg() Decimal {
-	return Decimal{
-		decimal: d.decimal.Neg(),
-		vars:    neg + leftParen + d.vars + rightParen,
-		formula: neg + leftParen + d.formula + rightParen,
-	}
+	return newOp(d.decimal.Neg(), OpNeg, nil, d)
 }
 
 // Mul returns d * d2.
 func (d Decimal) Mul(d2 Decimal) Decimal {
-	dec := Decimal{decimal: d.decimal.Mul(d2.decimal)}
-	var vars, formula string
-
-	if d.parens {
-		vars += leftParen + d.vars + rightParen + mul
-		formula += leftParen + d.formula + rightParen + mul
-	} else {
-		vars += d.vars + mul
-		formula += d.formula + mul
-	}
-
-	if d2.parens {
-		vars += leftParen + d2.vars + rightParen
-		formula += leftParen + d2.formula + rightParen
-	} else {
-		vars += d2.vars
-		formula += d2.formula
-	}
-
-	dec.vars = vars
-	dec.formula = formula
-
-	return dec
+	return newOp(d.decimal.Mul(d2.decimal), OpMul, nil, d, d2)
 }
 
 // Shift shifts the decimal in base 10.
@@ -443,40 +423,16 @@ func (d Decimal) Mul(d2 Decimal) Decimal {
 // In simpler terms, the given value for shift is added to the exponent
 // of the decimal.
 func (d Decimal) Shift(s int32) Decimal {
-	places := strconv.Itoa(int(s))
-	return Decimal{
-		decimal: d.decimal.Shift(s),
-		vars:    shift + leftParen + places + rightParen + leftParen + d.vars + rightParen,
-		formula: shift + leftParen + places + rightParen + leftParen + d.formula + rightParen,
-	}
+	return newOp(d.decimal.Shift(s), OpShift, []int32{s}, d)
 }
 
 // Div returns d / d2. If it doesn't divide exactly, the result will have
-// DivisionPrecision digits after the decimal point.
+// DivisionPrecision digits after the decimal point, unless d has a Context.
 func (d Decimal) Div(d2 Decimal) Decimal {
-	dec := Decimal{decimal: d.decimal.Div(d2.decimal)}
-
-	var vars, formula string
-	if d.parens {
-		vars += leftParen + d.vars + rightParen + div
-		formula += leftParen + d.formula + rightParen + div
-	} else {
-		vars += d.vars + div
-		formula += d.formula + div
+	if c := operandsContext(d, d2); c != nil {
+		return c.Div(d, d2)
 	}
-
-	if d2.parens {
-		vars += leftParen + d2.vars + rightParen
-		formula += leftParen + d2.formula + rightParen
-	} else {
-		vars += d2.vars
-		formula += d2.formula
-	}
-
-	dec.vars = vars
-	dec.formula = formula
-
-	return dec
+	return newOp(d.decimal.Div(d2.decimal), OpDiv, nil, d, d2)
 }
 
 // QuoRem does divsion with remainder
@@ -487,27 +443,9 @@ func (d Decimal) Div(d2 Decimal) Decimal {
 // Note that precision<0 is allowed as input.
 func (d Decimal) QuoRem(d2 Decimal, precision int32) (Decimal, Decimal) {
 	d3, d4 := d.decimal.QuoRem(d2.decimal, precision)
-	p := strconv.Itoa(int(precision))
-
-	var vars, formula string
-	if d.parens {
-		vars += quoRem + leftParen + p + rightParen + leftParen + leftParen + d.vars + rightParen + div
-		formula += quoRem + leftParen + p + rightParen + leftParen + leftParen + d.formula + rightParen + div
-	} else {
-		vars += quoRem + leftParen + p + rightParen + leftParen + d.vars + div
-		formula += quoRem + leftParen + p + rightParen + leftParen + d.formula + div
-	}
 
-	if d2.parens {
-		vars += leftParen + d2.vars + rightParen + rightParen
-		formula += leftParen + d2.formula + rightParen + rightParen
-	} else {
-		vars += d2.vars + rightParen
-		formula += d2.formula + rightParen
-	}
-
-	return Decimal{name: d.name + d2.name + "Quotient", decimal: d3, vars: vars, formula: formula},
-		Decimal{name: d.name + d2.name + "Remainder", decimal: d4, vars: vars, formula: formula}
+	return newOp(d3, OpQuoRem, []int32{precision, 0}, d, d2).SetName(d.name + d2.name + "Quotient"),
+		newOp(d4, OpQuoRem, []int32{precision, 1}, d, d2).SetName(d.name + d2.name + remainder)
 }
 
 // DivRound divides and rounds to a given precision
@@ -516,84 +454,17 @@ func (d Decimal) QuoRem(d2 Decimal, precision int32) (Decimal, Decimal) {
 //   if the quotient is negative then digit 5 is rounded down, away from 0
 // Note that precision<0 is allowed as input.
 func (d Decimal) DivRound(d2 Decimal, precision int32) Decimal {
-	dec := Decimal{decimal: d.decimal.DivRound(d2.decimal, precision)}
-	p := strconv.Itoa(int(precision))
-
-	var vars, formula string
-	if d.parens {
-		vars += divRound + leftParen + p + rightParen + leftParen + leftParen + d.vars + rightParen + div
-		formula += divRound + leftParen + p + rightParen + leftParen + leftParen + d.formula + rightParen + div
-	} else {
-		vars += divRound + leftParen + p + rightParen + leftParen + d.vars + div
-		formula += divRound + leftParen + p + rightParen + leftParen + d.formula + div
-	}
-
-	if d2.parens {
-		vars += leftParen + d2.vars + rightParen + rightParen
-		formula += leftParen + d2.formula + rightParen + rightParen
-	} else {
-		vars += d2.vars + rightParen
-		formula += d2.formula + rightParen
-	}
-
-	dec.vars = vars
-	dec.formula = formula
-
-	return dec
+	return newOp(d.decimal.DivRound(d2.decimal, precision), OpDivRound, []int32{precision}, d, d2)
 }
 
 // Mod returns d % d2.
 func (d Decimal) Mod(d2 Decimal) Decimal {
-	dec := Decimal{decimal: d.decimal.Mod(d2.decimal)}
-
-	var vars, formula string
-	if d.parens {
-		vars += leftParen + d.vars + rightParen + mod
-		formula += leftParen + d.formula + rightParen + mod
-	} else {
-		vars += d.vars + mod
-		formula += d.formula + mod
-	}
-
-	if d2.parens {
-		vars += leftParen + d2.vars + rightParen
-		formula += leftParen + d2.formula + rightParen
-	} else {
-		vars += d2.vars
-		formula += d2.formula
-	}
-
-	dec.vars = vars
-	dec.formula = formula
-
-	return dec
+	return newOp(d.decimal.Mod(d2.decimal), OpMod, nil, d, d2)
 }
 
 // Pow returns d to the power d2
 func (d Decimal) Pow(d2 Decimal) Decimal {
-	dec := Decimal{decimal: d.decimal.Pow(d2.decimal)}
-
-	var vars, formula string
-	if d.parens {
-		vars += leftParen + d.vars + rightParen + pow
-		formula += leftParen + d.formula + rightParen + pow
-	} else {
-		vars += d.vars + pow
-		formula += d.formula + pow
-	}
-
-	if d2.parens {
-		vars += leftParen + d2.vars + rightParen
-		formula += leftParen + d2.formula + rightParen
-	} else {
-		vars += d2.vars
-		formula += d2.formula
-	}
-
-	dec.vars = vars
-	dec.formula = formula
-
-	return dec
+	return newOp(d.decimal.Pow(d2.decimal), OpPow, nil, d, d2)
 }
 
 // Cmp compares the numbers represented by d and d2 and returns:
@@ -776,13 +647,7 @@ func (d Decimal) StringFixedCash(interval uint8) string {
 // 	   NewFromFloat(545).Round(-1).String() // output: "550"
 //
 func (d Decimal) Round(places int32) Decimal {
-	p := strconv.Itoa(int(places))
-
-	return Decimal{
-		decimal: d.decimal.Round(places),
-		vars:    round + leftParen + p + rightParen + leftParen + d.vars + rightParen,
-		formula: round + leftParen + p + rightParen + leftParen + d.formula + rightParen,
-	}
+	return newOp(d.decimal.Round(places), OpRound, []int32{places}, d)
 }
 
 // RoundBank rounds the decimal to places decimal places.
@@ -801,13 +666,7 @@ func (d Decimal) Round(places int32) Decimal {
 // 	   NewFromFloat(555).Round(-1).String() // output: "560"
 //
 func (d Decimal) RoundBank(places int32) Decimal {
-	p := strconv.Itoa(int(places))
-
-	return Decimal{
-		decimal: d.decimal.RoundBank(places),
-		vars:    roundBank + leftParen + p + rightParen + leftParen + d.vars + rightParen,
-		formula: roundBank + leftParen + p + rightParen + leftParen + d.formula + rightParen,
-	}
+	return newOp(d.decimal.RoundBank(places), OpRoundBank, []int32{places}, d)
 }
 
 // RoundCash aka Cash/Penny/öre rounding rounds decimal to a specific
@@ -821,31 +680,17 @@ func (d Decimal) RoundBank(places int32) Decimal {
 // 	  100: 100 cent rounding 3.50 => 4.00
 // For more details: https://en.wikipedia.org/wiki/Cash_rounding
 func (d Decimal) RoundCash(interval uint8) Decimal {
-	i := strconv.Itoa(int(interval))
-
-	return Decimal{
-		decimal: d.decimal.RoundCash(interval),
-		vars:    roundCash + leftParen + i + rightParen + leftParen + d.vars + rightParen,
-		formula: roundCash + leftParen + i + rightParen + leftParen + d.formula + rightParen,
-	}
+	return newOp(d.decimal.RoundCash(interval), OpRoundCash, []int32{int32(interval)}, d)
 }
 
 // Floor returns the nearest integer value less than or equal to d.
 func (d Decimal) Floor() Decimal {
-	return Decimal{
-		decimal: d.decimal.Floor(),
-		vars:    floor + leftParen + d.vars + rightParen,
-		formula: floor + leftParen + d.formula + rightParen,
-	}
+	return newOp(d.decimal.Floor(), OpFloor, nil, d)
 }
 
 // Ceil returns the nearest integer value greater than or equal to d.
 func (d Decimal) Ceil() Decimal {
-	return Decimal{
-		decimal: d.decimal.Ceil(),
-		vars:    ceil + leftParen + d.vars + rightParen,
-		formula: ceil + leftParen + d.formula + rightParen,
-	}
+	return newOp(d.decimal.Ceil(), OpCeil, nil, d)
 }
 
 // Truncate truncates off digits from the number, without rounding.
@@ -857,42 +702,50 @@ func (d Decimal) Ceil() Decimal {
 //     decimal.NewFromString("123.456").Truncate(2).String() // "123.45"
 //
 func (d Decimal) Truncate(precision int32) Decimal {
-	p := strconv.Itoa(int(precision))
-
-	return Decimal{
-		decimal: d.decimal.Truncate(precision),
-		vars:    truncate + leftParen + p + rightParen + leftParen + d.vars + rightParen,
-		formula: truncate + leftParen + p + rightParen + leftParen + d.formula + rightParen,
-	}
+	return newOp(d.decimal.Truncate(precision), OpTruncate, []int32{precision}, d)
 }
 
 // UnmarshalJSON implements the json.Unmarshaler interface.
 func (d *Decimal) UnmarshalJSON(decimalBytes []byte) error {
+	if isEnvelope(decimalBytes) {
+		return d.unmarshalEnvelope(decimalBytes)
+	}
+
 	if err := d.decimal.UnmarshalJSON(decimalBytes); err != nil {
 		return err
 	}
-	d.formula = d.String()
+	d.expr = &Expr{name: d.name, value: d.decimal}
 
 	return nil
 }
 
 // MarshalJSON implements the json.Marshaler interface.
 func (d Decimal) MarshalJSON() ([]byte, error) {
+	if MarshalEnvelope {
+		return d.marshalEnvelope()
+	}
 	return d.decimal.MarshalJSON()
 }
 
 // UnmarshalBinary implements the encoding.BinaryUnmarshaler interface. As a string representation
 // is already used when encoding to text, this method stores that string as []byte
 func (d *Decimal) UnmarshalBinary(data []byte) error {
+	if bytes.HasPrefix(data, binaryEnvelope) {
+		return d.unmarshalEnvelope(data[len(binaryEnvelope):])
+	}
+
 	if err := d.decimal.UnmarshalBinary(data); err != nil {
 		return err
 	}
-	d.formula = d.String()
+	d.expr = &Expr{name: d.name, value: d.decimal}
 	return nil
 }
 
 // MarshalBinary implements the encoding.BinaryMarshaler interface.
 func (d Decimal) MarshalBinary() (data []byte, err error) {
+	if MarshalEnvelope {
+		return d.marshalBinaryEnvelope()
+	}
 	return d.decimal.MarshalBinary()
 }
 
@@ -901,7 +754,7 @@ func (d *Decimal) Scan(value interface{}) error {
 	if err := d.decimal.Scan(value); err != nil {
 		return err
 	}
-	d.formula = d.String()
+	d.expr = &Expr{name: d.name, value: d.decimal}
 	return nil
 }
 
@@ -913,31 +766,34 @@ func (d Decimal) Value() (driver.Value, error) {
 // UnmarshalText implements the encoding.TextUnmarshaler interface for XML
 // deserialization.
 func (d *Decimal) UnmarshalText(text []byte) error {
+	if isEnvelope(text) {
+		return d.unmarshalEnvelope(text)
+	}
+
 	if err := d.decimal.UnmarshalText(text); err != nil {
 		return err
 	}
-	d.formula = d.String()
+	d.expr = &Expr{name: d.name, value: d.decimal}
 	return nil
 }
 
 // MarshalText implements the encoding.TextMarshaler interface for XML
 // serialization.
 func (d Decimal) MarshalText() (text []byte, err error) {
+	if MarshalEnvelope {
+		return d.marshalEnvelope()
+	}
 	return d.decimal.MarshalText()
 }
 
 // GobEncode implements the gob.GobEncoder interface for gob serialization.
 func (d Decimal) GobEncode() ([]byte, error) {
-	return d.decimal.GobEncode()
+	return d.MarshalBinary()
 }
 
 // GobDecode implements the gob.GobDecoder interface for gob serialization.
 func (d *Decimal) GobDecode(data []byte) error {
-	if err := d.decimal.UnmarshalBinary(data); err != nil {
-		return err
-	}
-	d.formula = d.String()
-	return d.decimal.GobDecode(data)
+	return d.UnmarshalBinary(data)
 }
 
 // StringScaled first scales the decimal then calls .String() on it.
@@ -954,23 +810,12 @@ func (d Decimal) StringScaled(exp int32) string {
 //
 // This makes it harder to accidentally call Min with 0 arguments.
 func Min(first Decimal, rest ...Decimal) Decimal {
-	varsList := make([]string, 1+len(rest))
-	varsList[0] = first.vars
-	formulaList := make([]string, 1+len(rest))
-	formulaList[0] = first.formula
-
 	newRest := make([]decimal.Decimal, len(rest))
 	for i, r := range rest {
 		newRest[i] = r.decimal
-		varsList[i+1] = r.vars
-		formulaList[i+1] = r.formula
 	}
 
-	return Decimal{
-		decimal: decimal.Min(first.decimal, newRest...),
-		vars:    min + leftParen + strings.Join(varsList, comma) + rightParen,
-		formula: min + leftParen + strings.Join(formulaList, comma) + rightParen,
-	}
+	return newOp(decimal.Min(first.decimal, newRest...), OpMin, nil, append([]Decimal{first}, rest...)...)
 }
 
 // Max returns the largest Decimal that was passed in the arguments.
@@ -981,72 +826,38 @@ func Min(first Decimal, rest ...Decimal) Decimal {
 //
 // This makes it harder to accidentally call Max with 0 arguments.
 func Max(first Decimal, rest ...Decimal) Decimal {
-	varsList := make([]string, 1+len(rest))
-	varsList[0] = first.vars
-	formulaList := make([]string, 1+len(rest))
-	formulaList[0] = first.formula
-
 	newRest := make([]decimal.Decimal, len(rest))
 	for i, r := range rest {
 		newRest[i] = r.decimal
-		varsList[i+1] = r.vars
-		formulaList[i+1] = r.formula
 	}
 
-	return Decimal{
-		decimal: decimal.Max(first.decimal, newRest...),
-		vars:    max + leftParen + strings.Join(varsList, comma) + rightParen,
-		formula: max + leftParen + strings.Join(formulaList, comma) + rightParen,
-	}
+	return newOp(decimal.Max(first.decimal, newRest...), OpMax, nil, append([]Decimal{first}, rest...)...)
 }
 
 // Sum returns the combined total of the provided first and rest Decimals
 func Sum(first Decimal, rest ...Decimal) Decimal {
-	varsList := make([]string, 1+len(rest))
-	varsList[0] = first.vars
-	formulaList := make([]string, 1+len(rest))
-	formulaList[0] = first.formula
-
 	newRest := make([]decimal.Decimal, len(rest))
 	for i, r := range rest {
 		newRest[i] = r.decimal
-		varsList[i+1] = r.vars
-		formulaList[i+1] = r.formula
 	}
 
-	return Decimal{
-		decimal: decimal.Sum(first.decimal, newRest...),
-		vars:    sum + leftParen + strings.Join(varsList, comma) + rightParen,
-		formula: sum + leftParen + strings.Join(formulaList, comma) + rightParen,
-	}
+	return newOp(decimal.Sum(first.decimal, newRest...), OpSum, nil, append([]Decimal{first}, rest...)...)
 }
 
 // Avg returns the average value of the provided first and rest Decimals
 func Avg(first Decimal, rest ...Decimal) Decimal {
-	varsList := make([]string, 1+len(rest))
-	varsList[0] = first.vars
-	formulaList := make([]string, 1+len(rest))
-	formulaList[0] = first.formula
-
 	newRest := make([]decimal.Decimal, len(rest))
 	for i, r := range rest {
 		newRest[i] = r.decimal
-		varsList[i+1] = r.vars
-		formulaList[i+1] = r.formula
 	}
 
-	return Decimal{
-		decimal: decimal.Avg(first.decimal, newRest...),
-		vars:    avg + leftParen + strings.Join(varsList, comma) + rightParen,
-		formula: avg + leftParen + strings.Join(formulaList, comma) + rightParen,
-	}
+	return newOp(decimal.Avg(first.decimal, newRest...), OpAvg, nil, append([]Decimal{first}, rest...)...)
 }
 
 // RescalePair rescales two decimals to common exponential value (minimal exp of both decimals)
 func RescalePair(d1 Decimal, d2 Decimal) (Decimal, Decimal) {
 	d3, d4 := decimal.RescalePair(d1.decimal, d2.decimal)
-	return Decimal{name: d1.name, decimal: d3, vars: d1.name, formula: d3.String()},
-		Decimal{name: d2.name, decimal: d4, vars: d2.name, formula: d4.String()}
+	return newLeaf(d1.name, d3), newLeaf(d2.name, d4)
 }
 
 func (d NullDecimal) Valid() bool {
@@ -1054,12 +865,7 @@ func (d NullDecimal) Valid() bool {
 }
 
 func (d NullDecimal) Decimal() Decimal {
-	return Decimal{
-		name:    d.name,
-		decimal: d.decimal.Decimal,
-		vars:    d.name,
-		formula: d.decimal.Decimal.String(),
-	}
+	return newLeaf(d.name, d.decimal.Decimal)
 }
 
 // Scan implements the sql.Scanner interface for database deserialization.
@@ -1083,37 +889,136 @@ func (d NullDecimal) MarshalJSON() ([]byte, error) {
 }
 
 // Atan returns the arctangent, in radians, of x.
+//
+// Atan, Sin, Cos and Tan are computed by github.com/shopspring/decimal unless d
+// has a Context, in which case they are computed to its precision.
 func (d Decimal) Atan() Decimal {
-	return Decimal{
-		decimal: d.decimal.Atan(),
-		vars:    atan + leftParen + d.vars + rightParen,
-		formula: atan + leftParen + d.formula + rightParen,
+	if d.context != nil {
+		return d.unary(OpAtan, atanAt)
 	}
+	return newOp(d.decimal.Atan(), OpAtan, nil, d)
 }
 
 // Sin returns the sine of the radian argument x.
 func (d Decimal) Sin() Decimal {
-	return Decimal{
-		decimal: d.decimal.Sin(),
-		vars:    sin + leftParen + d.vars + rightParen,
-		formula: sin + leftParen + d.formula + rightParen,
+	if d.context != nil {
+		return d.unary(OpSin, sinAt)
 	}
+	return newOp(d.decimal.Sin(), OpSin, nil, d)
 }
 
 // Cos returns the cosine of the radian argument x.
 func (d Decimal) Cos() Decimal {
-	return Decimal{
-		decimal: d.decimal.Cos(),
-		vars:    cos + leftParen + d.vars + rightParen,
-		formula: cos + leftParen + d.formula + rightParen,
+	if d.context != nil {
+		return d.unary(OpCos, cosAt)
 	}
+	return newOp(d.decimal.Cos(), OpCos, nil, d)
 }
 
 // Tan returns the tangent of the radian argument x.
 func (d Decimal) Tan() Decimal {
-	return Decimal{
-		decimal: d.decimal.Tan(),
-		vars:    tan + leftParen + d.vars + rightParen,
-		formula: tan + leftParen + d.formula + rightParen,
+	if d.context != nil {
+		return d.unary(OpTan, tanAt)
 	}
+	return newOp(d.decimal.Tan(), OpTan, nil, d)
+}
+
+// Asin returns the arcsine, in radians, of x. It panics if x is not within
+// [-1, 1].
+//
+// Asin, Acos, Atan2, the hyperbolic functions, Radians and Degrees round their
+// result to decimal.DivisionPrecision digits after the decimal point like Div()
+// does, unless d has a Context.
+func (d Decimal) Asin() Decimal {
+	return d.unary(OpAsin, asinAt)
+}
+
+// Acos returns the arccosine, in radians, of x. It panics if x is not within
+// [-1, 1].
+func (d Decimal) Acos() Decimal {
+	return d.unary(OpAcos, acosAt)
+}
+
+// Atan2 returns the arctangent, in radians, of y/x using the signs of both to
+// determine the quadrant of the result, with y the decimal. It returns 0 when
+// both are 0.
+func (d Decimal) Atan2(x Decimal) Decimal {
+	c, params := roundingContext(d, x)
+	return newOp(c.round(atan2At(d.decimal, x.decimal, workPrec(c.Precision))), OpAtan2, params, d, x)
+}
+
+// Sinh returns the hyperbolic sine of x.
+func (d Decimal) Sinh() Decimal {
+	return d.unary(OpSinh, sinhAt)
+}
+
+// Cosh returns the hyperbolic cosine of x.
+func (d Decimal) Cosh() Decimal {
+	return d.unary(OpCosh, coshAt)
+}
+
+// Tanh returns the hyperbolic tangent of x.
+func (d Decimal) Tanh() Decimal {
+	return d.unary(OpTanh, tanhAt)
+}
+
+// Asinh returns the inverse hyperbolic sine of x.
+func (d Decimal) Asinh() Decimal {
+	return d.unary(OpAsinh, asinhAt)
+}
+
+// Acosh returns the inverse hyperbolic cosine of x. It panics if x is less
+// than 1.
+func (d Decimal) Acosh() Decimal {
+	return d.unary(OpAcosh, acoshAt)
+}
+
+// Atanh returns the inverse hyperbolic tangent of x. It panics if x is not
+// within (-1, 1).
+func (d Decimal) Atanh() Decimal {
+	return d.unary(OpAtanh, atanhAt)
+}
+
+// Radians converts the angle x from degrees to radians.
+func (d Decimal) Radians() Decimal {
+	return d.unary(OpRadians, radiansAt)
+}
+
+// Degrees converts the angle x from radians to degrees.
+func (d Decimal) Degrees() Decimal {
+	return d.unary(OpDegrees, degreesAt)
+}
+
+// Sqrt returns the square root of d rounded to precision digits after the
+// decimal point. It panics if d is negative.
+//
+// Math() renders it as "sqrt(var1)" when precision is decimal.DivisionPrecision
+// and with its precision as Round() does otherwise, ex: "sqrt(4)(var1)". So do
+// Exp(), Ln(), Log10() and Log(), ex: "log(var1, var2)" or "log(4)(var1, var2)".
+func (d Decimal) Sqrt(precision int32) Decimal {
+	return newOp(sqrtDecimal(d.decimal, precision), OpSqrt, []int32{precision}, d)
+}
+
+// Exp returns e to the power d rounded to precision digits after the decimal
+// point.
+func (d Decimal) Exp(precision int32) Decimal {
+	return newOp(expDecimal(d.decimal, precision), OpExp, []int32{precision}, d)
+}
+
+// Ln returns the natural logarithm of d rounded to precision digits after the
+// decimal point. It panics if d is not positive.
+func (d Decimal) Ln(precision int32) Decimal {
+	return newOp(lnDecimal(d.decimal, precision), OpLn, []int32{precision}, d)
+}
+
+// Log10 returns the decimal logarithm of d rounded to precision digits after
+// the decimal point. It panics if d is not positive.
+func (d Decimal) Log10(precision int32) Decimal {
+	return newOp(logDecimal(d.decimal, decimal.New(10, 0), precision), OpLog10, []int32{precision}, d)
+}
+
+// Log returns the logarithm of d in base rounded to precision digits after the
+// decimal point. It panics if d or base is not positive or if base is 1.
+func (d Decimal) Log(base Decimal, precision int32) Decimal {
+	return newOp(logDecimal(d.decimal, base.decimal, precision), OpLog, []int32{precision}, d, base)
 }
@@ -224,8 +224,8 @@ func TestQuoRem(t *testing.T) {
 	assert.Equal(t, "quoRem(3)(4.333 / 2.7) = 1.604", formula)
 
 	vars, formula = d2.Math()
-	assert.Equal(t, "quoRem(3)(var1 / var2) = var1var2Remainder", vars)
-	assert.Equal(t, "quoRem(3)(4.333 / 2.7) = 0.0022", formula)
+	assert.Equal(t, "quoRem(3, rem)(var1 / var2) = var1var2Remainder", vars)
+	assert.Equal(t, "quoRem(3, rem)(4.333 / 2.7) = 0.0022", formula)
 }
 
 func TestMod(t *testing.T) {
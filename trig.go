@@ -0,0 +1,229 @@
+package tomath
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+// pi holds the first 100 digits of π. Higher precisions compute it.
+var pi = decimal.RequireFromString("3.141592653589793238462643383279502884197169399375105820974944592307816406286208998628034825342117068")
+
+// atanReduced bounds the argument of the arctangent series.
+var atanReduced = decimal.New(1, -1)
+
+// The functions below return their result to about wp digits after the
+// decimal point. The methods round it with a Context.
+
+// piAt returns π.
+func piAt(wp int32) decimal.Decimal {
+	if wp < 95 {
+		return pi.Round(wp + 2)
+	}
+
+	// Machin's formula: π = 16 * atan(1/5) - 4 * atan(1/239).
+	p := wp + minWorkPrec
+	return atanInv(5, p).Mul(decimal.New(16, 0)).Sub(atanInv(239, p).Mul(decimal.New(4, 0))).Round(wp)
+}
+
+// atanInv returns the arctangent of 1/n to about p digits after the decimal
+// point.
+func atanInv(n int64, p int32) decimal.Decimal {
+	n2 := decimal.New(n*n, 0)
+	power := one.DivRound(decimal.New(n, 0), p)
+	sum := power
+	for k := int64(3); ; k += 2 {
+		power = power.DivRound(n2, p).Neg()
+		t := power.DivRound(decimal.New(k, 0), p)
+		if t.IsZero() {
+			break
+		}
+		sum = sum.Add(t)
+	}
+	return sum
+}
+
+func atanAt(x decimal.Decimal, wp int32) decimal.Decimal {
+	p := wp + minWorkPrec
+	y := x.Abs()
+	invert := y.Cmp(one) > 0
+	if invert {
+		y = one.DivRound(y, p)
+	}
+
+	// atan(y) = 2 * atan(y / (1 + sqrt(1 + y^2))) brings y below atanReduced
+	// for the series y - y^3/3 + y^5/5 - ... to converge quickly.
+	k := int32(0)
+	for y.Cmp(atanReduced) > 0 {
+		y = y.DivRound(one.Add(sqrtDecimal(one.Add(y.Mul(y)), p)), p)
+		k++
+	}
+
+	y2 := y.Mul(y).Round(p)
+	sum, term := y, y
+	for n := int64(3); ; n += 2 {
+		term = term.Mul(y2).Round(p).Neg()
+		t := term.DivRound(decimal.New(n, 0), p)
+		if t.IsZero() {
+			break
+		}
+		sum = sum.Add(t)
+	}
+	sum = sum.Mul(decimal.New(1<<uint(k), 0))
+
+	if invert {
+		sum = piAt(p).Mul(half).Sub(sum)
+	}
+	if x.Sign() < 0 {
+		sum = sum.Neg()
+	}
+
+	return sum.Round(wp)
+}
+
+// reduceAngle returns x minus the nearest multiple of 2π to about p digits
+// after the decimal point.
+func reduceAngle(x decimal.Decimal, p int32) decimal.Decimal {
+	m := magnitude(x)
+	if m < 0 {
+		m = 0
+	}
+	twoPi := piAt(p + m).Mul(two)
+	return x.Sub(x.DivRound(twoPi, 0).Mul(twoPi)).Round(p)
+}
+
+func sinAt(x decimal.Decimal, wp int32) decimal.Decimal {
+	p := wp + minWorkPrec
+	y := reduceAngle(x, p)
+	y2 := y.Mul(y).Round(p)
+	sum, term := y, y
+	for n := int64(2); ; n += 2 {
+		term = term.Mul(y2).DivRound(decimal.New(-n*(n+1), 0), p)
+		if term.IsZero() {
+			break
+		}
+		sum = sum.Add(term)
+	}
+	return sum.Round(wp)
+}
+
+func cosAt(x decimal.Decimal, wp int32) decimal.Decimal {
+	p := wp + minWorkPrec
+	y := reduceAngle(x, p)
+	y2 := y.Mul(y).Round(p)
+	sum, term := one, one
+	for n := int64(1); ; n += 2 {
+		term = term.Mul(y2).DivRound(decimal.New(-n*(n+1), 0), p)
+		if term.IsZero() {
+			break
+		}
+		sum = sum.Add(term)
+	}
+	return sum.Round(wp)
+}
+
+func tanAt(x decimal.Decimal, wp int32) decimal.Decimal {
+	// The error of the cosine is amplified by the square of its inverse.
+	p := wp + minWorkPrec
+	c := cosAt(x, p)
+	if extra := -2 * magnitude(c); extra > 0 {
+		p += extra
+		c = cosAt(x, p)
+	}
+	if c.IsZero() {
+		panic("tomath: tangent of an odd multiple of π/2")
+	}
+	return sinAt(x, p).DivRound(c, wp)
+}
+
+func asinAt(x decimal.Decimal, wp int32) decimal.Decimal {
+	if x.Abs().Cmp(one) > 0 {
+		panic("tomath: asin argument out of range")
+	}
+
+	p := wp + minWorkPrec
+	if x.Abs().Equal(one) {
+		return piAt(p).Mul(half).Mul(decimal.New(int64(x.Sign()), 0)).Round(wp)
+	}
+
+	return atanAt(x.DivRound(sqrtDecimal(one.Sub(x.Mul(x)), p), p), wp)
+}
+
+func acosAt(x decimal.Decimal, wp int32) decimal.Decimal {
+	p := wp + minWorkPrec
+	return piAt(p).Mul(half).Sub(asinAt(x, p)).Round(wp)
+}
+
+// atan2At returns the arctangent of y/x using the signs of both to determine
+// the quadrant.
+func atan2At(y, x decimal.Decimal, wp int32) decimal.Decimal {
+	p := wp + minWorkPrec
+	switch {
+	case x.Sign() > 0:
+		return atanAt(y.DivRound(x, p), wp)
+	case x.Sign() < 0 && y.Sign() >= 0:
+		return atanAt(y.DivRound(x, p), p).Add(piAt(p)).Round(wp)
+	case x.Sign() < 0:
+		return atanAt(y.DivRound(x, p), p).Sub(piAt(p)).Round(wp)
+	}
+
+	return piAt(p).Mul(half).Mul(decimal.New(int64(y.Sign()), 0)).Round(wp)
+}
+
+func sinhAt(x decimal.Decimal, wp int32) decimal.Decimal {
+	p := wp + minWorkPrec
+	return expDecimal(x, p).Sub(expDecimal(x.Neg(), p)).Mul(half).Round(wp)
+}
+
+func coshAt(x decimal.Decimal, wp int32) decimal.Decimal {
+	p := wp + minWorkPrec
+	return expDecimal(x, p).Add(expDecimal(x.Neg(), p)).Mul(half).Round(wp)
+}
+
+func tanhAt(x decimal.Decimal, wp int32) decimal.Decimal {
+	p := wp + minWorkPrec
+	e, e2 := expDecimal(x, p), expDecimal(x.Neg(), p)
+	return e.Sub(e2).DivRound(e.Add(e2), wp)
+}
+
+func asinhAt(x decimal.Decimal, wp int32) decimal.Decimal {
+	// asinh is odd, computing it on |x| avoids the cancellation of
+	// x + sqrt(x^2 + 1) for negative x.
+	p := wp + minWorkPrec
+	a := x.Abs()
+	return lnAt(a.Add(sqrtDecimal(a.Mul(a).Add(one), p)), p).Mul(decimal.New(int64(x.Sign()), 0)).Round(wp)
+}
+
+func acoshAt(x decimal.Decimal, wp int32) decimal.Decimal {
+	if x.Cmp(one) < 0 {
+		panic("tomath: acosh argument out of range")
+	}
+
+	p := wp + minWorkPrec
+	return lnAt(x.Add(sqrtDecimal(x.Mul(x).Sub(one), p)), p).Round(wp)
+}
+
+func atanhAt(x decimal.Decimal, wp int32) decimal.Decimal {
+	if x.Abs().Cmp(one) >= 0 {
+		panic("tomath: atanh argument out of range")
+	}
+
+	p := wp + minWorkPrec
+	return lnAt(one.Add(x).DivRound(one.Sub(x), p), p).Mul(half).Round(wp)
+}
+
+// radiansAt converts x from degrees to radians.
+func radiansAt(x decimal.Decimal, wp int32) decimal.Decimal {
+	return x.Mul(piAt(anglePrec(x, wp))).DivRound(decimal.New(180, 0), wp)
+}
+
+// degreesAt converts x from radians to degrees.
+func degreesAt(x decimal.Decimal, wp int32) decimal.Decimal {
+	return x.Mul(decimal.New(180, 0)).DivRound(piAt(anglePrec(x, wp)), wp)
+}
+
+// anglePrec returns the precision of π converting the angle x.
+func anglePrec(x decimal.Decimal, wp int32) int32 {
+	if m := magnitude(x); m > 0 {
+		wp += m
+	}
+	return wp + minWorkPrec
+}
@@ -0,0 +1,71 @@
+package tomath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInverseTrig(t *testing.T) {
+	tests := map[string]Decimal{
+		"0.5235987755982989":  NewFromFloat(0.5).Asin(),
+		"-1.5707963267948966": NewFromInt(-1).Asin(),
+		"0":                   NewFromInt(0).Asin(),
+		"2.0943951023931955":  NewFromFloat(-0.5).Acos(),
+		"0.7853981633974483":  NewFromInt(1).Atan2(NewFromInt(1)),
+		"2.3561944901923449":  NewFromInt(1).Atan2(NewFromInt(-1)),
+		"-2.3561944901923449": NewFromInt(-1).Atan2(NewFromInt(-1)),
+		"1.5707963267948966":  NewFromInt(1).Atan2(NewFromInt(0)),
+		"3.1415926535897932":  NewFromInt(0).Atan2(NewFromInt(-1)),
+	}
+
+	for want, d := range tests {
+		assert.Equal(t, want, d.String())
+	}
+
+	assert.Equal(t, "0", NewFromInt(1).Acos().String())
+	assert.Equal(t, "0", NewFromInt(0).Atan2(NewFromInt(0)).String())
+	assert.PanicsWithValue(t, "tomath: asin argument out of range", func() { NewFromFloat(1.5).Asin() })
+	assert.PanicsWithValue(t, "tomath: asin argument out of range", func() { NewFromInt(-2).Acos() })
+}
+
+func TestHyperbolic(t *testing.T) {
+	tests := map[string]Decimal{
+		"1.1752011936438015":  NewFromInt(1).Sinh(),
+		"1.5430806348152438":  NewFromInt(1).Cosh(),
+		"-0.4621171572600098": NewFromFloat(-0.5).Tanh(),
+		"-1.4436354751788103": NewFromInt(-2).Asinh(),
+		"1.3169578969248167":  NewFromInt(2).Acosh(),
+		"0.5493061443340548":  NewFromFloat(0.5).Atanh(),
+		"0":                   NewFromInt(0).Sinh(),
+	}
+
+	for want, d := range tests {
+		assert.Equal(t, want, d.String())
+	}
+
+	assert.PanicsWithValue(t, "tomath: acosh argument out of range", func() { NewFromFloat(0.5).Acosh() })
+	assert.PanicsWithValue(t, "tomath: atanh argument out of range", func() { NewFromInt(1).Atanh() })
+}
+
+func TestAngleConversion(t *testing.T) {
+	assert.Equal(t, "3.1415926535897932", NewFromInt(180).Radians().String())
+	assert.Equal(t, "0.7853981633974483", NewFromInt(45).Radians().String())
+	assert.Equal(t, "57.2957795130823209", NewFromInt(1).Degrees().String())
+	assert.Equal(t, "90", NewFromInt(90).Radians().Degrees().Round(10).String())
+}
+
+func TestTrigMath(t *testing.T) {
+	lat1 := NewFromFloatWithName("lat1", 48.85).Radians()
+	lat2 := NewFromFloatWithName("lat2", 51.5).Radians()
+	half := NewFromFloatWithName("half", 0.5)
+	dLat := lat2.Sub(lat1).Mul(half).Sin()
+
+	a := dLat.Mul(dLat).SetName("a")
+	vars, _ := a.Math()
+	assert.Equal(t, "sin((radians(lat2) - radians(lat1)) * half) * sin((radians(lat2) - radians(lat1)) * half) = a", vars)
+
+	vars, formula := NewFromFloatWithName("y", 1).Atan2(NewFromFloatWithName("x", -1)).Degrees().SetName("bearing").Math()
+	assert.Equal(t, "degrees(atan2(y, x)) = bearing", vars)
+	assert.Equal(t, "degrees(atan2(1, -1)) = 134.9999999999999983", formula)
+}
@@ -0,0 +1,272 @@
+package tomath
+
+import (
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	unitMul = "*"
+	unitDiv = "/"
+	unitPow = "^"
+)
+
+// Unit is a unit of measure: a product of symbols raised to integer powers,
+// ex: "GB", "USD/GB" or "m^2". The symbols of the common units of data, time,
+// length and mass convert to one another, every other symbol, ex: "USD", is a
+// dimension of its own.
+//
+// The zero value is dimensionless.
+type Unit struct {
+	terms []unitTerm
+}
+
+type unitTerm struct {
+	symbol string
+	power  int
+}
+
+// baseUnit is the dimension of a symbol and its size in the base unit of the
+// dimension.
+type baseUnit struct {
+	dimension string
+	factor    decimal.Decimal
+}
+
+// units holds the symbols which convert to one another. Their factors only
+// have 2, 3 and 5 as prime factors so that every conversion multiplies or
+// divides by an exact decimal.
+var units = map[string]baseUnit{
+	"bit": {"data", decimal.New(125, -3)},
+	"B":   {"data", decimal.New(1, 0)},
+	"KB":  {"data", decimal.New(1, 3)},
+	"MB":  {"data", decimal.New(1, 6)},
+	"GB":  {"data", decimal.New(1, 9)},
+	"TB":  {"data", decimal.New(1, 12)},
+	"PB":  {"data", decimal.New(1, 15)},
+	"KiB": {"data", decimal.New(1<<10, 0)},
+	"MiB": {"data", decimal.New(1<<20, 0)},
+	"GiB": {"data", decimal.New(1<<30, 0)},
+	"TiB": {"data", decimal.New(1<<40, 0)},
+
+	"ms":   {"time", decimal.New(1, -3)},
+	"s":    {"time", decimal.New(1, 0)},
+	"min":  {"time", decimal.New(60, 0)},
+	"h":    {"time", decimal.New(3600, 0)},
+	"hour": {"time", decimal.New(3600, 0)},
+	"day":  {"time", decimal.New(86400, 0)},
+
+	"mm": {"length", decimal.New(1, -3)},
+	"cm": {"length", decimal.New(1, -2)},
+	"m":  {"length", decimal.New(1, 0)},
+	"km": {"length", decimal.New(1, 3)},
+
+	"mg": {"mass", decimal.New(1, -3)},
+	"g":  {"mass", decimal.New(1, 0)},
+	"kg": {"mass", decimal.New(1, 3)},
+	"t":  {"mass", decimal.New(1, 6)},
+}
+
+// ParseUnit returns the unit written s, ex: "GB", "USD/GB/h", "kg*m/s^2" or
+// "1/s". Each "/" divides by the symbol following it only. The empty string
+// is dimensionless.
+func ParseUnit(s string) (Unit, error) {
+	var u Unit
+	if s == "" {
+		return u, nil
+	}
+
+	invalid := errors.New("tomath: invalid unit " + strconv.Quote(s))
+	sign := 1
+	for i, rest := 0, s; ; i++ {
+		end := strings.IndexAny(rest, unitMul+unitDiv)
+		if end < 0 {
+			end = len(rest)
+		}
+
+		symbol, power := rest[:end], 1
+		if p := strings.Index(symbol, unitPow); p >= 0 {
+			n, err := strconv.Atoi(symbol[p+len(unitPow):])
+			if err != nil {
+				return Unit{}, invalid
+			}
+			symbol, power = symbol[:p], n
+		}
+
+		switch {
+		case i == 0 && symbol == "1" && power == 1 && end < len(rest) && rest[end:end+1] == unitDiv:
+		case validSymbol(symbol):
+			u = u.Mul(Unit{terms: []unitTerm{{symbol, sign * power}}})
+		default:
+			return Unit{}, invalid
+		}
+
+		if end == len(rest) {
+			return u, nil
+		}
+		sign = 1
+		if rest[end:end+1] == unitDiv {
+			sign = -1
+		}
+		rest = rest[end+1:]
+	}
+}
+
+// validSymbol returns whether s is made of letters, digits and underscores and
+// starts with a letter.
+func validSymbol(s string) bool {
+	for i, r := range s {
+		if !unicode.IsLetter(r) && (i == 0 || !unicode.IsDigit(r) && r != '_') {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// String returns the unit as written by ParseUnit(), ex: "USD/GB".
+func (u Unit) String() string {
+	var b strings.Builder
+	for _, t := range u.terms {
+		if t.power > 0 {
+			if b.Len() > 0 {
+				b.WriteString(unitMul)
+			}
+			b.WriteString(t.symbol + powerSuffix(t.power))
+		}
+	}
+	if b.Len() == 0 && len(u.terms) > 0 {
+		b.WriteString("1")
+	}
+	for _, t := range u.terms {
+		if t.power < 0 {
+			b.WriteString(unitDiv + t.symbol + powerSuffix(-t.power))
+		}
+	}
+	return b.String()
+}
+
+func powerSuffix(power int) string {
+	if power == 1 {
+		return ""
+	}
+	return unitPow + strconv.Itoa(power)
+}
+
+// Mul returns the product of u and u2. The powers of the symbols they share
+// add up, ex: "GB" times "USD/GB" is "USD".
+func (u Unit) Mul(u2 Unit) Unit {
+	terms := append([]unitTerm(nil), u.terms...)
+	for _, t2 := range u2.terms {
+		found := false
+		for i, t := range terms {
+			if t.symbol == t2.symbol {
+				terms[i].power += t2.power
+				found = true
+				break
+			}
+		}
+		if !found {
+			terms = append(terms, t2)
+		}
+	}
+
+	u = Unit{}
+	for _, t := range terms {
+		if t.power != 0 {
+			u.terms = append(u.terms, t)
+		}
+	}
+	return u
+}
+
+// Div returns the quotient of u by u2.
+func (u Unit) Div(u2 Unit) Unit {
+	return u.Mul(u2.Pow(-1))
+}
+
+// Pow returns u raised to the power n.
+func (u Unit) Pow(n int) Unit {
+	if n == 0 {
+		return Unit{}
+	}
+	p := Unit{terms: make([]unitTerm, len(u.terms))}
+	for i, t := range u.terms {
+		p.terms[i] = unitTerm{t.symbol, t.power * n}
+	}
+	return p
+}
+
+// Equal returns whether u and u2 have the same symbols with the same powers.
+func (u Unit) Equal(u2 Unit) bool {
+	return len(u.Div(u2).terms) == 0
+}
+
+// Compatible returns whether u converts to u2, ex: "MB/s" and "GB/h".
+func (u Unit) Compatible(u2 Unit) bool {
+	d := u.Div(u2).dimension()
+	for _, power := range d {
+		if power != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// dimension returns the powers of the dimensions of u.
+func (u Unit) dimension() map[string]int {
+	d := map[string]int{}
+	for _, t := range u.terms {
+		dimension := t.symbol
+		if b, ok := units[t.symbol]; ok {
+			dimension = b.dimension
+		}
+		d[dimension] += t.power
+	}
+	return d
+}
+
+// factor returns the size of u in the base units of its dimensions.
+func (u Unit) factor() *big.Rat {
+	f := big.NewRat(1, 1)
+	for _, t := range u.terms {
+		b, ok := units[t.symbol]
+		if !ok {
+			continue
+		}
+		r := b.factor.Rat()
+		if t.power < 0 {
+			r.Inv(r)
+		}
+		for i := 0; i < t.power || i < -t.power; i++ {
+			f.Mul(f, r)
+		}
+	}
+	return f
+}
+
+// exactDecimal returns r as a decimal if it has a finite decimal expansion.
+func exactDecimal(r *big.Rat) (decimal.Decimal, bool) {
+	q := new(big.Int).Set(r.Denom())
+	k := int32(0)
+	for _, p := range []int64{2, 5} {
+		n := int32(0)
+		for new(big.Int).Mod(q, big.NewInt(p)).Sign() == 0 {
+			q.Quo(q, big.NewInt(p))
+			n++
+		}
+		if n > k {
+			k = n
+		}
+	}
+	if q.Cmp(big.NewInt(1)) != 0 {
+		return decimal.Decimal{}, false
+	}
+
+	n := new(big.Int).Mul(r.Num(), pow10(k))
+	return decimal.NewFromBigInt(n.Quo(n, r.Denom()), -k), true
+}
@@ -0,0 +1,73 @@
+package tomath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func requireUnit(t *testing.T, s string) Unit {
+	t.Helper()
+	u, err := ParseUnit(s)
+	require.NoError(t, err)
+	return u
+}
+
+func TestParseUnit(t *testing.T) {
+	tests := map[string]string{
+		"":          "",
+		"GB":        "GB",
+		"USD/GB":    "USD/GB",
+		"USD/GB/h":  "USD/GB/h",
+		"kg*m/s^2":  "kg*m/s^2",
+		"1/s":       "1/s",
+		"m^2":       "m^2",
+		"s^-1":      "1/s",
+		"GB*GB/GB":  "GB",
+		"GB/GB":     "",
+		"USD/GB*GB": "USD",
+		"m_2":       "m_2",
+	}
+
+	for in, want := range tests {
+		assert.Equal(t, want, requireUnit(t, in).String(), in)
+	}
+
+	for _, in := range []string{"1", "2GB", "GB/", "/GB", "GB^", "GB^x", "G B", "GB**h", "1*s"} {
+		_, err := ParseUnit(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestUnitAlgebra(t *testing.T) {
+	gb, price, h := requireUnit(t, "GB"), requireUnit(t, "USD/GB"), requireUnit(t, "h")
+
+	assert.Equal(t, "USD", gb.Mul(price).String())
+	assert.Equal(t, "GB/h", gb.Div(h).String())
+	assert.Equal(t, "GB^3", gb.Pow(3).String())
+	assert.Equal(t, "1/GB^2", gb.Pow(-2).String())
+	assert.Equal(t, "", gb.Pow(0).String())
+	assert.True(t, requireUnit(t, "kg*m").Equal(requireUnit(t, "m*kg")))
+	assert.False(t, gb.Equal(requireUnit(t, "MB")))
+}
+
+func TestUnitCompatible(t *testing.T) {
+	tests := map[[2]string]bool{
+		{"GB", "MB"}:          true,
+		{"GB", "GiB"}:         true,
+		{"MB/s", "GB/h"}:      true,
+		{"min", "hour"}:       true,
+		{"USD/GB", "USD/TB"}:  true,
+		{"GB", "h"}:           false,
+		{"USD", "EUR"}:        false,
+		{"USD/GB", "USD"}:     false,
+		{"GB/MB", ""}:         true,
+		{"km/h", "m/s"}:       true,
+		{"kg*m/s^2", "g*m/s"}: false,
+	}
+
+	for units, want := range tests {
+		assert.Equal(t, want, requireUnit(t, units[0]).Compatible(requireUnit(t, units[1])), units)
+	}
+}